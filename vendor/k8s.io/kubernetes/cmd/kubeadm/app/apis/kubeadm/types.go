@@ -0,0 +1,154 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeadm contains the internal, canonical types used to configure
+// the kubeadm CLI and the cluster components it manages.
+package kubeadm
+
+// ImageMeta allows to customize the image used for components that are not
+// originated from the Kubernetes/Kubernetes release process.
+type ImageMeta struct {
+	// ImageRepository sets the container registry to pull images from.
+	ImageRepository string
+	// ImageTag allows to specify a tag for the image.
+	ImageTag string
+}
+
+// APIEndpoint struct contains elements of API server instance deployed on a node.
+type APIEndpoint struct {
+	// AdvertiseAddress sets the IP address for the API server to advertise.
+	AdvertiseAddress string
+	// BindPort sets the secure port for the API Server to bind to.
+	BindPort int32
+}
+
+// Etcd contains elements describing Etcd configuration.
+type Etcd struct {
+	// Local provides configuration knobs for configuring the local etcd instance.
+	// Local and External are mutually exclusive.
+	Local *LocalEtcd
+
+	// External describes how to connect to an external etcd cluster.
+	// Local and External are mutually exclusive.
+	External *ExternalEtcd
+
+	// Events, if set, describes a second etcd cluster dedicated to the /events
+	// resource prefix, reusing the same Local/External shape as the main
+	// cluster. The API server is configured to route events to it via
+	// --etcd-servers-overrides.
+	Events *Etcd
+}
+
+// LocalEtcd describes that kubeadm should run an etcd cluster locally.
+type LocalEtcd struct {
+	ImageMeta
+
+	// DataDir is the directory etcd will place its data.
+	DataDir string
+
+	// ExtraArgs are extra arguments provided to the etcd binary when run inside a static pod.
+	ExtraArgs map[string]string
+
+	// ServerCertSANs sets extra Subject Alternative Names for the etcd server signing cert.
+	ServerCertSANs []string
+
+	// PeerCertSANs sets extra Subject Alternative Names for the etcd peer signing cert.
+	PeerCertSANs []string
+
+	// EtcdctlSidecar, if set, adds a long-running etcdctl sidecar container to the
+	// etcd static pod so operators have a stable `kubectl exec` target with
+	// ETCDCTL_API=3 etcdctl already pointed at the local member and its certs.
+	EtcdctlSidecar *EtcdctlSidecar
+
+	// Backup, if set, configures scheduled etcd snapshot backups for this member.
+	Backup *EtcdBackup
+
+	// BootstrapCerts, if true, adds an init container to the etcd static pod
+	// that self-requests the server/peer certs for this node via the
+	// CertificateSigningRequest API when they aren't already present on disk,
+	// so HA control-plane joins don't require the admin to pre-copy etcd certs
+	// for every new member.
+	BootstrapCerts bool
+
+	// BootstrapCertsImage customizes the image used for the etcd-bootstrap-certs
+	// init container. That container runs `kubeadm etcd bootstrap-certs`, so it
+	// needs a kubeadm binary on its PATH, unlike the etcd image used by the rest
+	// of this pod; defaults to DefaultKubeadmImageRepository/DefaultKubeadmImageTag
+	// when unset.
+	BootstrapCertsImage ImageMeta
+}
+
+// EtcdBackup configures scheduled snapshot backups of a local etcd cluster,
+// taken via `etcdctl snapshot save` and run as a Kubernetes CronJob.
+type EtcdBackup struct {
+	// Schedule is a Cron expression (e.g. "0 * * * *") describing how often to
+	// take a snapshot.
+	Schedule string
+
+	// Retention is the number of snapshots to keep; older snapshots beyond this
+	// count are pruned. A value of 0 means no pruning.
+	Retention int32
+
+	// Destination configures where snapshot files are written.
+	Destination BackupDestination
+}
+
+// BackupDestination configures the storage backing etcd snapshot backups.
+// Exactly one of HostPath or PersistentVolumeClaim should be set.
+type BackupDestination struct {
+	// HostPath is a path on the control-plane node's filesystem to write snapshots to.
+	HostPath string
+
+	// PersistentVolumeClaim is the name of a PVC to write snapshots to.
+	PersistentVolumeClaim string
+}
+
+// EtcdctlSidecar configures the optional etcdctl sidecar container added to the
+// local etcd static pod.
+type EtcdctlSidecar struct {
+	// CPURequest is the CPU resource request for the sidecar container, e.g. "10m".
+	CPURequest string
+
+	// MemoryRequest is the memory resource request for the sidecar container, e.g. "16Mi".
+	MemoryRequest string
+}
+
+// ExternalEtcd describes an external etcd cluster.
+type ExternalEtcd struct {
+	// Endpoints of etcd members.
+	Endpoints []string
+
+	// CAFile is an SSL Certificate Authority file used to secure etcd communication.
+	CAFile string
+
+	// CertFile is an SSL certification file used to secure etcd communication.
+	CertFile string
+
+	// KeyFile is an SSL key file used to secure etcd communication.
+	KeyFile string
+}
+
+// InitConfiguration contains a list of elements that is specific "kubeadm init"-only runtime information.
+type InitConfiguration struct {
+	// LocalAPIEndpoint represents the endpoint of the API server instance that's deployed on this control-plane node.
+	LocalAPIEndpoint APIEndpoint
+}
+
+// ClusterConfiguration contains cluster-wide configuration for a kubeadm cluster.
+type ClusterConfiguration struct {
+	// Etcd holds configuration for etcd.
+	Etcd Etcd
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/approver"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd/backup"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/csr"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// NewCmdEtcd returns the "kubeadm etcd" command and its subcommands.
+func NewCmdEtcd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Manage the local etcd cluster backing the control plane",
+	}
+
+	cmd.AddCommand(newCmdEtcdBackup(out))
+	cmd.AddCommand(newCmdEtcdSnapshot(out))
+	cmd.AddCommand(newCmdEtcdBootstrapCerts(out))
+	cmd.AddCommand(newCmdEtcdCheck(out))
+
+	return cmd
+}
+
+func newCmdEtcdBackup(out io.Writer) *cobra.Command {
+	var advertiseAddress string
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Take an immediate etcd snapshot backup into /var/backups/etcd",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSnapshotSave(out, advertiseAddress, "")
+		},
+	}
+	cmd.Flags().StringVar(&advertiseAddress, "advertise-address", "", "Advertise address of the local etcd member")
+	return cmd
+}
+
+func newCmdEtcdSnapshot(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Take or restore an etcd snapshot",
+	}
+	cmd.AddCommand(newCmdEtcdSnapshotSave(out))
+	cmd.AddCommand(newCmdEtcdSnapshotRestore(out))
+	return cmd
+}
+
+func newCmdEtcdSnapshotSave(out io.Writer) *cobra.Command {
+	var advertiseAddress, destination string
+
+	cmd := &cobra.Command{
+		Use:   "save",
+		Short: "Take a snapshot of the local etcd member",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSnapshotSave(out, advertiseAddress, destination)
+		},
+	}
+	cmd.Flags().StringVar(&advertiseAddress, "advertise-address", "", "Advertise address of the local etcd member")
+	cmd.Flags().StringVar(&destination, "destination", "", "Directory to write the snapshot file to; defaults to /var/backups/etcd (the member's configured backup destination is not read)")
+	return cmd
+}
+
+func newCmdEtcdSnapshotRestore(out io.Writer) *cobra.Command {
+	var manifestDir, nodeName, advertiseAddress, config string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <snapshot-file>",
+		Short: "Restore the local etcd member's data directory from a snapshot file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkRestoreIsCoordinated(config, force); err != nil {
+				return err
+			}
+
+			opts := backup.RestoreOptions{
+				SnapshotPath: args[0],
+				ManifestDir:  manifestDir,
+				NodeName:     nodeName,
+				InitConfig: &kubeadmapi.InitConfiguration{
+					LocalAPIEndpoint: kubeadmapi.APIEndpoint{AdvertiseAddress: advertiseAddress},
+				},
+			}
+			if err := backup.Restore(opts, nil); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "etcd member %q restored from %q\n", nodeName, args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&manifestDir, "manifest-dir", constants.KubernetesDir+"/"+constants.ManifestsSubDirName, "Directory containing the etcd static pod manifest")
+	cmd.Flags().StringVar(&nodeName, "name", "", "Name of the etcd member being restored")
+	cmd.Flags().StringVar(&advertiseAddress, "advertise-address", "", "Advertise address used to compute the member's peer URL")
+	cmd.Flags().StringVar(&config, "config", "", "Path to a kubeadm ClusterConfiguration file, used to check whether this is a multi-member HA etcd cluster before restoring")
+	cmd.Flags().BoolVar(&force, "force", false, "Proceed even though the HA coordination check was skipped or failed; the operator is responsible for restoring only one member at a time")
+	return cmd
+}
+
+// checkRestoreIsCoordinated guards against running `snapshot restore`
+// concurrently against every member of an HA etcd cluster: restoring more
+// than one member from a snapshot at the same time corrupts the cluster, and
+// Restore itself has no way to detect or prevent that. It requires --config
+// so etcdutil.CheckConfigurationIsHA can inspect the cluster's etcd
+// configuration, and refuses to proceed against an HA cluster unless the
+// operator passes --force to confirm they are coordinating the restore
+// themselves (e.g. by restoring one member at a time with the others
+// stopped).
+func checkRestoreIsCoordinated(config string, force bool) error {
+	if force {
+		return nil
+	}
+
+	if config == "" {
+		return fmt.Errorf("--config is required so kubeadm can check whether this is a multi-member HA etcd cluster before restoring; pass --force to skip this check")
+	}
+
+	cfg, err := loadClusterConfiguration(config)
+	if err != nil {
+		return err
+	}
+
+	if etcdutil.CheckConfigurationIsHA(&cfg.Etcd) {
+		return fmt.Errorf("this cluster's etcd configuration is HA (more than one external endpoint); snapshot restore must be run against only one member at a time, with the rest of the cluster coordinated separately. Pass --force once that coordination is in place")
+	}
+	return nil
+}
+
+// runSnapshotSave shells out to etcdctl using the same invocation the backup
+// CronJob uses, so `kubeadm etcd backup`/`snapshot save` and the scheduled
+// CronJob always agree on how a snapshot is taken.
+func runSnapshotSave(out io.Writer, advertiseAddress, destination string) error {
+	endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: advertiseAddress}
+	if err := backup.SaveSnapshot(endpoint, destination); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "etcd snapshot saved")
+	return nil
+}
+
+// newCmdEtcdBootstrapCerts returns the hidden "kubeadm etcd bootstrap-certs"
+// command run by the etcd-bootstrap-certs init container. It is not meant to
+// be invoked directly by operators; the command line it expects is built by
+// bootstrapCertsInitContainer in phases/etcd/bootstrap.go.
+func newCmdEtcdBootstrapCerts(out io.Writer) *cobra.Command {
+	var certDir, nodeName, sanConfig, extraSANs, kubeconfig string
+
+	cmd := &cobra.Command{
+		Use:    "bootstrap-certs",
+		Short:  "Request this node's etcd server and peer certificates if they are not already on disk",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := kubeconfigClientSet(kubeconfig)
+			if err != nil {
+				return err
+			}
+			return runBootstrapCerts(out, client, certDir, nodeName, sanConfig, extraSANs)
+		},
+	}
+	cmd.Flags().StringVar(&certDir, "cert-dir", constants.EtcdCertAndKeyDir, "Directory to write the server and peer certificate/key pairs to")
+	cmd.Flags().StringVar(&nodeName, "node-name", "", "Name of the node the certificates are being requested for")
+	cmd.Flags().StringVar(&sanConfig, "san-config", "", "Directory of a projected ConfigMap supplying additional per-node SANs")
+	cmd.Flags().StringVar(&extraSANs, "extra-sans", "", "Comma-separated list of extra SANs to request on both the server and peer certificates; the init container passes LocalEtcd.ServerCertSANs and PeerCertSANs here")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", filepath.Join(constants.KubernetesDir, constants.KubeletKubeConfigFileName), "Path to the kubeconfig used to submit the CertificateSigningRequests")
+	return cmd
+}
+
+// runBootstrapCerts requests the server and peer certificate/key pairs for
+// nodeName, skipping each pair that is already present on disk, so restarts
+// of an already-bootstrapped member are a no-op.
+func runBootstrapCerts(out io.Writer, client clientset.Interface, certDir, nodeName, sanConfig, extraSANs string) error {
+	var dnsNames, ipAddresses []string
+	for _, san := range mergeSANs(nodeName, sanConfig, extraSANs) {
+		if net.ParseIP(san) != nil {
+			ipAddresses = append(ipAddresses, san)
+		} else {
+			dnsNames = append(dnsNames, san)
+		}
+	}
+
+	requests := []struct {
+		name       string
+		signerName string
+		certFile   string
+		keyFile    string
+	}{
+		{
+			name:       fmt.Sprintf("etcd-server-%s", nodeName),
+			signerName: approver.EtcdServerSignerName,
+			certFile:   filepath.Join(certDir, constants.EtcdServerCertName),
+			keyFile:    filepath.Join(certDir, constants.EtcdServerKeyName),
+		},
+		{
+			name:       fmt.Sprintf("etcd-peer-%s", nodeName),
+			signerName: approver.EtcdPeerSignerName,
+			certFile:   filepath.Join(certDir, constants.EtcdPeerCertName),
+			keyFile:    filepath.Join(certDir, constants.EtcdPeerKeyName),
+		},
+	}
+
+	for _, r := range requests {
+		opts := csr.RequestOptions{
+			Name:       r.name,
+			SignerName: r.signerName,
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageDigitalSignature,
+				certificatesv1.UsageKeyEncipherment,
+				certificatesv1.UsageServerAuth,
+				certificatesv1.UsageClientAuth,
+			},
+			DNSNames:    dnsNames,
+			IPAddresses: ipAddresses,
+		}
+		if err := csr.EnsureCert(client, opts, r.certFile, r.keyFile); err != nil {
+			return fmt.Errorf("failed to bootstrap certificate %q: %v", r.name, err)
+		}
+	}
+
+	fmt.Fprintf(out, "etcd bootstrap certificates ready for node %q\n", nodeName)
+	return nil
+}
+
+func mergeSANs(nodeName, sanConfig, extraSANs string) []string {
+	sans := []string{nodeName}
+	if extraSANs != "" {
+		sans = append(sans, strings.Split(extraSANs, ",")...)
+	}
+	if sanConfig != "" {
+		sans = append(sans, readSANConfig(sanConfig)...)
+	}
+	return sans
+}
+
+// readSANConfig reads the extra SANs projected from the etcd-bootstrap-sans
+// ConfigMap, one SAN per file, keyed by file name. It is optional: a missing
+// or empty directory (no ConfigMap provided for this node) yields no SANs.
+func readSANConfig(dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var sans []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if san := strings.TrimSpace(string(data)); san != "" {
+			sans = append(sans, san)
+		}
+	}
+	return sans
+}
+
+func kubeconfigClientSet(kubeconfig string) (clientset.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %q: %v", kubeconfig, err)
+	}
+	return clientset.NewForConfig(config)
+}
+
+// newCmdEtcdCheck returns the "kubeadm etcd check" command, which runs
+// etcd.Diagnostics and renders the results so CI pipelines and
+// cluster-lifecycle operators can gate upgrades on etcd health.
+func newCmdEtcdCheck(out io.Writer) *cobra.Command {
+	var manifestDir, certDir, advertiseAddress, config, output string
+	var warnOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Run etcd health diagnostics and report the results",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadClusterConfiguration(config)
+			if err != nil {
+				return err
+			}
+
+			diag := &etcd.Diagnostics{
+				ManifestDir: manifestDir,
+				CertDir:     certDir,
+				Cfg:         cfg,
+				Endpoint:    &kubeadmapi.APIEndpoint{AdvertiseAddress: advertiseAddress},
+			}
+			results := diag.Run()
+
+			if err := etcd.WriteReport(out, results, etcd.ReportFormat(output)); err != nil {
+				return err
+			}
+
+			if !warnOnly && etcd.AnyFailed(results) {
+				return fmt.Errorf("one or more etcd health checks failed")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&manifestDir, "manifest-dir", constants.KubernetesDir+"/"+constants.ManifestsSubDirName, "Directory containing the etcd static pod manifest")
+	cmd.Flags().StringVar(&certDir, "cert-dir", constants.EtcdCertAndKeyDir, "Directory containing the etcd PKI material")
+	cmd.Flags().StringVar(&advertiseAddress, "advertise-address", "", "Advertise address of the local etcd member")
+	cmd.Flags().StringVar(&config, "config", "", "Path to a kubeadm ClusterConfiguration file, used to run the HA configuration check; the check is skipped if omitted")
+	cmd.Flags().StringVar(&output, "output", string(etcd.ReportFormatText), "Output format: text, json or junit")
+	cmd.Flags().BoolVar(&warnOnly, "warn-only", false, "Report failing checks without a nonzero exit code")
+	return cmd
+}
+
+// loadClusterConfiguration reads a kubeadm ClusterConfiguration from path, if
+// one is given. An empty path is not an error: it leaves Diagnostics.Cfg nil,
+// so checkConfiguration reports CheckSkipped instead of failing the whole run.
+func loadClusterConfiguration(path string) (*kubeadmapi.ClusterConfiguration, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster configuration %q: %v", path, err)
+	}
+
+	cfg := &kubeadmapi.ClusterConfiguration{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cluster configuration %q: %v", path, err)
+	}
+	return cfg, nil
+}
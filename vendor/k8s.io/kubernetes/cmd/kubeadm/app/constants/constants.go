@@ -0,0 +1,109 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package constants holds the shared values used throughout the kubeadm
+// codebase, such as well-known file paths, ports and default images.
+package constants
+
+const (
+	// EtcdListenClientPort defines the port etcd listens on for client traffic.
+	EtcdListenClientPort = 2379
+
+	// EtcdListenPeerPort defines the port etcd listens on for peer traffic.
+	EtcdListenPeerPort = 2380
+
+	// EtcdMetricsPort defines the port etcd listens on, unauthenticated and
+	// over plain HTTP, for metrics and health checks that can't present a
+	// client certificate (e.g. the kubelet's httpGet probes).
+	EtcdMetricsPort = 2381
+
+	// EtcdDataDir defines the directory where etcd stores its data on a local etcd member.
+	EtcdDataDir = "/var/lib/etcd"
+
+	// KubernetesDir is the directory kubeadm uses for storing kubernetes configuration files.
+	KubernetesDir = "/etc/kubernetes"
+
+	// ManifestsSubDirName defines the directory under KubernetesDir where static pod manifests are kept.
+	ManifestsSubDirName = "manifests"
+
+	// DefaultCertificateDir defines the default directory where kubeadm stores certificates.
+	DefaultCertificateDir = "/etc/kubernetes/pki"
+
+	// EtcdCertAndKeyDir defines the directory where etcd certificates are stored.
+	EtcdCertAndKeyDir = DefaultCertificateDir + "/etcd"
+
+	// EtcdCACertName defines the etcd CA certificate name.
+	EtcdCACertName = "ca.crt"
+
+	// EtcdServerCertName defines the etcd server certificate name.
+	EtcdServerCertName = "server.crt"
+
+	// EtcdServerKeyName defines the etcd server key name.
+	EtcdServerKeyName = "server.key"
+
+	// EtcdPeerCertName defines the etcd peer certificate name.
+	EtcdPeerCertName = "peer.crt"
+
+	// EtcdPeerKeyName defines the etcd peer key name.
+	EtcdPeerKeyName = "peer.key"
+
+	// EtcdHealthcheckClientCertName defines the etcd healthcheck-client certificate name.
+	EtcdHealthcheckClientCertName = "healthcheck-client.crt"
+
+	// EtcdHealthcheckClientKeyName defines the etcd healthcheck-client key name.
+	EtcdHealthcheckClientKeyName = "healthcheck-client.key"
+
+	// DefaultEtcdImageRepository is the default image repository used for the etcd image.
+	DefaultEtcdImageRepository = "k8s.gcr.io"
+
+	// DefaultEtcdImageTag is the default image tag used for the etcd image.
+	DefaultEtcdImageTag = "3.4.3-0"
+
+	// EtcdImageName is the base name of the etcd image.
+	EtcdImageName = "etcd"
+
+	// DefaultKubeadmImageRepository is the default image repository used for
+	// the kubeadm image, i.e. the image that needs a kubeadm binary on its
+	// PATH, such as the etcd-bootstrap-certs init container.
+	DefaultKubeadmImageRepository = "k8s.gcr.io"
+
+	// DefaultKubeadmImageTag is the default image tag used for the kubeadm image.
+	DefaultKubeadmImageTag = "latest"
+
+	// KubeadmImageName is the base name of the kubeadm image.
+	KubeadmImageName = "kubeadm"
+
+	// EventsEtcdListenClientPort defines the port the dedicated events etcd member listens on for client traffic.
+	EventsEtcdListenClientPort = 2382
+
+	// EventsEtcdListenPeerPort defines the port the dedicated events etcd member listens on for peer traffic.
+	EventsEtcdListenPeerPort = 2383
+
+	// EventsEtcdMetricsPort defines the port the dedicated events etcd member
+	// listens on, unauthenticated and over plain HTTP, for metrics and health
+	// checks that can't present a client certificate.
+	EventsEtcdMetricsPort = 2384
+
+	// EventsEtcdDataDir defines the directory where the dedicated events etcd member stores its data.
+	EventsEtcdDataDir = "/var/lib/etcd-events"
+
+	// EventsEtcdCertAndKeyDir defines the directory where the dedicated events etcd member's certificates are stored.
+	EventsEtcdCertAndKeyDir = DefaultCertificateDir + "/etcd-events"
+
+	// KubeletKubeConfigFileName defines the file name for the kubeconfig that the
+	// kubelet, and anything running as one of its static pods, uses to reach the API server.
+	KubeletKubeConfigFileName = "kubelet.conf"
+)
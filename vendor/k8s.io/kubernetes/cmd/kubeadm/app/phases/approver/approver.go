@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package approver installs the controller that auto-approves the
+// CertificateSigningRequests the etcd-bootstrap-certs init container submits
+// for the etcd server and peer signers, mirroring the node-bootstrap
+// auto-approval the kubelet itself gets for its own client certificate.
+package approver
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+)
+
+const (
+	// EtcdServerSignerName is the signer used for CertificateSigningRequests
+	// requesting an etcd server certificate.
+	EtcdServerSignerName = "etcd.kubeadm.k8s.io/server"
+
+	// EtcdPeerSignerName is the signer used for CertificateSigningRequests
+	// requesting an etcd peer certificate.
+	EtcdPeerSignerName = "etcd.kubeadm.k8s.io/peer"
+
+	// EtcdServersGroup is the RBAC group the approver trusts to request
+	// certificates from EtcdServerSignerName.
+	EtcdServersGroup = "system:etcd-servers"
+
+	// EtcdPeersGroup is the RBAC group the approver trusts to request
+	// certificates from EtcdPeerSignerName.
+	EtcdPeersGroup = "system:etcd-peers"
+
+	// DeploymentName is the name of the auto-approver controller's Deployment.
+	DeploymentName = "kubeadm-etcd-approver"
+
+	namespace = "kube-system"
+)
+
+// EnsureAutoApprover installs the RBAC and Deployment for the etcd CSR
+// auto-approver controller. It is idempotent: the RBAC objects are created
+// once and left alone on subsequent calls, while the Deployment is updated
+// in place so a `kubeadm init`/`join` that bumps the image repository or tag
+// still takes effect.
+func EnsureAutoApprover(client clientset.Interface, imageRepository, imageTag string) error {
+	ctx := context.Background()
+
+	if err := createOrUpdateServiceAccount(ctx, client); err != nil {
+		return err
+	}
+	if err := createOrUpdateClusterRole(ctx, client); err != nil {
+		return err
+	}
+	if err := createOrUpdateClusterRoleBinding(ctx, client); err != nil {
+		return err
+	}
+	if err := createOrUpdateDeployment(ctx, client, approverImage(imageRepository, imageTag)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func approverImage(repo, tag string) string {
+	if repo == "" {
+		repo = constants.DefaultEtcdImageRepository
+	}
+	if tag == "" {
+		tag = "v0.1.0"
+	}
+	return fmt.Sprintf("%s/%s:%s", repo, DeploymentName, tag)
+}
+
+func ignoreAlreadyExists(err error) error {
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
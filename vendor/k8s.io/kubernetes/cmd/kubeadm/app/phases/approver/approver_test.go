@@ -0,0 +1,64 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approver
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureAutoApprover(t *testing.T) {
+	client := fakeclientset.NewSimpleClientset()
+
+	if err := EnsureAutoApprover(client, "", ""); err != nil {
+		t.Fatalf("EnsureAutoApprover returned an error: %v", err)
+	}
+
+	if _, err := client.CoreV1().ServiceAccounts(namespace).Get(context.Background(), DeploymentName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected a ServiceAccount named %q, got: %v", DeploymentName, err)
+	}
+	if _, err := client.RbacV1().ClusterRoles().Get(context.Background(), DeploymentName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected a ClusterRole named %q, got: %v", DeploymentName, err)
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Get(context.Background(), DeploymentName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected a ClusterRoleBinding named %q, got: %v", DeploymentName, err)
+	}
+	dep, err := client.AppsV1().Deployments(namespace).Get(context.Background(), DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected a Deployment named %q, got: %v", DeploymentName, err)
+	}
+	if len(dep.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("expected exactly 1 container, got %d", len(dep.Spec.Template.Spec.Containers))
+	}
+
+	// Calling again must converge instead of failing on AlreadyExists, and
+	// must pick up a changed image.
+	if err := EnsureAutoApprover(client, "example.io/repo", "v2"); err != nil {
+		t.Fatalf("second EnsureAutoApprover call returned an error: %v", err)
+	}
+	dep, err = client.AppsV1().Deployments(namespace).Get(context.Background(), DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the Deployment to still exist, got: %v", err)
+	}
+	wantImage := "example.io/repo/kubeadm-etcd-approver:v2"
+	if got := dep.Spec.Template.Spec.Containers[0].Image; got != wantImage {
+		t.Errorf("expected updated image %q, got %q", wantImage, got)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approver
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+func createOrUpdateServiceAccount(ctx context.Context, client clientset.Interface) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DeploymentName,
+			Namespace: namespace,
+		},
+	}
+	_, err := client.CoreV1().ServiceAccounts(namespace).Create(ctx, sa, metav1.CreateOptions{})
+	return ignoreAlreadyExists(err)
+}
+
+// clusterRole grants only what the approver needs: list/watch/get on
+// CertificateSigningRequests, and permission to approve (update the approval
+// subresource) and read the signing result.
+func clusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: DeploymentName,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"certificates.k8s.io"},
+				Resources: []string{"certificatesigningrequests"},
+				Verbs:     []string{"get", "list", "watch"},
+			},
+			{
+				APIGroups: []string{"certificates.k8s.io"},
+				Resources: []string{"certificatesigningrequests/approval"},
+				Verbs:     []string{"update"},
+			},
+			{
+				APIGroups:     []string{"certificates.k8s.io"},
+				Resources:     []string{"signers"},
+				ResourceNames: []string{EtcdServerSignerName, EtcdPeerSignerName},
+				Verbs:         []string{"approve"},
+			},
+		},
+	}
+}
+
+func createOrUpdateClusterRole(ctx context.Context, client clientset.Interface) error {
+	_, err := client.RbacV1().ClusterRoles().Create(ctx, clusterRole(), metav1.CreateOptions{})
+	return ignoreAlreadyExists(err)
+}
+
+func clusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: DeploymentName,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     DeploymentName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      DeploymentName,
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+func createOrUpdateClusterRoleBinding(ctx context.Context, client clientset.Interface) error {
+	_, err := client.RbacV1().ClusterRoleBindings().Create(ctx, clusterRoleBinding(), metav1.CreateOptions{})
+	return ignoreAlreadyExists(err)
+}
+
+// deployment returns the auto-approver controller's Deployment. It runs a
+// single replica: double-approving the same CSR is harmless, but there's no
+// benefit to racing two controllers for the same watch.
+func deployment(image string) *appsv1.Deployment {
+	replicas := int32(1)
+	labels := map[string]string{"k8s-app": DeploymentName}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DeploymentName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: DeploymentName,
+					Tolerations: []corev1.Toleration{
+						{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+						{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  DeploymentName,
+							Image: image,
+							Args: []string{
+								"--signer-names=" + EtcdServerSignerName + "," + EtcdPeerSignerName,
+								"--trusted-groups=" + EtcdServersGroup + "," + EtcdPeersGroup,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func createOrUpdateDeployment(ctx context.Context, client clientset.Interface, image string) error {
+	deployments := client.AppsV1().Deployments(namespace)
+
+	_, err := deployments.Create(ctx, deployment(image), metav1.CreateOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, err := deployments.Get(ctx, DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	updated := deployment(image)
+	updated.ResourceVersion = existing.ResourceVersion
+	_, err = deployments.Update(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplane implements the kubeadm phases that generate the static
+// pod manifests for the control plane components (kube-apiserver,
+// kube-controller-manager and kube-scheduler).
+package controlplane
+
+import (
+	"fmt"
+	"strings"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// GetAPIServerCommand appends the kube-apiserver flags this package is
+// responsible for to baseArgs and returns the result. Today that's just
+// --etcd-servers-overrides, routing the /events resource prefix to a
+// dedicated events etcd cluster when one is configured.
+//
+// NOTE: upstream kubeadm builds the rest of the kube-apiserver static pod
+// command (certs, admission, authn/authz, ...) in
+// phases/controlplane/manifests.go, which is not part of this vendored
+// subset. Callers here must supply that base command themselves; once
+// manifests.go is vendored, its command builder should call this function
+// instead of constructing --etcd-servers-overrides on its own.
+func GetAPIServerCommand(cfg *kubeadmapi.ClusterConfiguration, endpoint *kubeadmapi.APIEndpoint, baseArgs []string) []string {
+	if arg := getEtcdServersOverridesArg(cfg, endpoint); arg != "" {
+		baseArgs = append(baseArgs, arg)
+	}
+	return baseArgs
+}
+
+// getEtcdServersOverridesArg returns the --etcd-servers-overrides flag to pass
+// to kube-apiserver so that the /events resource prefix is routed to a
+// dedicated events etcd cluster, if one is configured. It returns an empty
+// string when cfg.Etcd.Events is nil.
+func getEtcdServersOverridesArg(cfg *kubeadmapi.ClusterConfiguration, endpoint *kubeadmapi.APIEndpoint) string {
+	events := cfg.Etcd.Events
+	if events == nil {
+		return ""
+	}
+
+	var endpoints []string
+	switch {
+	case events.Local != nil:
+		endpoints = []string{etcdutil.GetEventsClientURL(&kubeadmapi.InitConfiguration{LocalAPIEndpoint: *endpoint})}
+	case events.External != nil:
+		endpoints = events.External.Endpoints
+	}
+
+	if len(endpoints) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("--etcd-servers-overrides=/events#%s", strings.Join(endpoints, ","))
+}
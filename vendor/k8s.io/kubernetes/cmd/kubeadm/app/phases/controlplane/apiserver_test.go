@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplane
+
+import (
+	"testing"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func TestGetEtcdServersOverridesArg(t *testing.T) {
+	var tests = []struct {
+		name             string
+		cfg              *kubeadmapi.ClusterConfiguration
+		advertiseAddress string
+		expected         string
+	}{
+		{
+			name:             "no events cluster configured",
+			cfg:              &kubeadmapi.ClusterConfiguration{},
+			advertiseAddress: "10.10.10.10",
+			expected:         "",
+		},
+		{
+			name: "local events cluster, IPv4 advertise address",
+			cfg: &kubeadmapi.ClusterConfiguration{
+				Etcd: kubeadmapi.Etcd{
+					Events: &kubeadmapi.Etcd{
+						Local: &kubeadmapi.LocalEtcd{},
+					},
+				},
+			},
+			advertiseAddress: "10.10.10.10",
+			expected:         "--etcd-servers-overrides=/events#https://10.10.10.10:2382",
+		},
+		{
+			name: "local events cluster, IPv6 advertise address",
+			cfg: &kubeadmapi.ClusterConfiguration{
+				Etcd: kubeadmapi.Etcd{
+					Events: &kubeadmapi.Etcd{
+						Local: &kubeadmapi.LocalEtcd{},
+					},
+				},
+			},
+			advertiseAddress: "2001:db8::2",
+			expected:         "--etcd-servers-overrides=/events#https://[2001:db8::2]:2382",
+		},
+		{
+			name: "external events cluster",
+			cfg: &kubeadmapi.ClusterConfiguration{
+				Etcd: kubeadmapi.Etcd{
+					Events: &kubeadmapi.Etcd{
+						External: &kubeadmapi.ExternalEtcd{
+							Endpoints: []string{"10.100.0.1:2382", "10.100.0.2:2382"},
+						},
+					},
+				},
+			},
+			advertiseAddress: "10.10.10.10",
+			expected:         "--etcd-servers-overrides=/events#10.100.0.1:2382,10.100.0.2:2382",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: test.advertiseAddress}
+			arg := getEtcdServersOverridesArg(test.cfg, endpoint)
+			if arg != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, arg)
+			}
+		})
+	}
+}
+
+func TestGetAPIServerCommand(t *testing.T) {
+	cfg := &kubeadmapi.ClusterConfiguration{
+		Etcd: kubeadmapi.Etcd{
+			Events: &kubeadmapi.Etcd{
+				Local: &kubeadmapi.LocalEtcd{},
+			},
+		},
+	}
+	endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: "10.10.10.10"}
+
+	baseArgs := []string{"kube-apiserver", "--advertise-address=10.10.10.10"}
+	args := GetAPIServerCommand(cfg, endpoint, baseArgs)
+
+	expected := append(append([]string{}, baseArgs...), "--etcd-servers-overrides=/events#https://10.10.10.10:2382")
+	if len(args) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, args)
+		}
+	}
+}
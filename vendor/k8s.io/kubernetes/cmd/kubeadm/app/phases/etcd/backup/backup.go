@@ -0,0 +1,174 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup generates the CronJob manifest kubeadm uses to take
+// scheduled snapshots of a local etcd cluster, and implements the
+// `kubeadm etcd snapshot save/restore` logic.
+package backup
+
+import (
+	"fmt"
+	"os/exec"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+const (
+	// CronJobName is the name given to the generated snapshot CronJob.
+	CronJobName = "etcd-snapshot-backup"
+
+	certsVolumeName = "etcd-certs"
+	destVolumeName  = "etcd-snapshot-dest"
+	destMountPath   = "/var/backups/etcd"
+)
+
+// GetSnapshotCronJob builds the CronJob that periodically runs `etcdctl
+// snapshot save` against the local etcd member, reusing the healthcheck
+// client certs already mounted into the etcd static pod. It returns nil if
+// local, or local.Backup, or local.Backup.Schedule is unset.
+func GetSnapshotCronJob(local *kubeadmapi.LocalEtcd, endpoint *kubeadmapi.APIEndpoint) *batchv1beta1.CronJob {
+	if local == nil || local.Backup == nil || local.Backup.Schedule == "" {
+		return nil
+	}
+
+	clientURL := etcdutil.GetClientURLByIP(endpoint.AdvertiseAddress)
+
+	container := v1.Container{
+		Name:    "etcd-snapshot",
+		Image:   etcdphase.EtcdImage(local),
+		Command: snapshotSaveCommand(clientURL, destMountPath, local.Backup.Retention),
+		VolumeMounts: []v1.VolumeMount{
+			{Name: certsVolumeName, MountPath: constants.EtcdCertAndKeyDir},
+			{Name: destVolumeName, MountPath: destMountPath},
+		},
+	}
+
+	podSpec := v1.PodSpec{
+		RestartPolicy: v1.RestartPolicyOnFailure,
+		HostNetwork:   true,
+		Containers:    []v1.Container{container},
+		Volumes: []v1.Volume{
+			newHostPathVolume(certsVolumeName, constants.EtcdCertAndKeyDir),
+			destinationVolume(local.Backup.Destination),
+		},
+	}
+
+	return &batchv1beta1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1beta1",
+			Kind:       "CronJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CronJobName,
+			Namespace: "kube-system",
+			Labels: map[string]string{
+				"component": "etcd",
+				"tier":      "control-plane",
+			},
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: local.Backup.Schedule,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: podSpec,
+					},
+				},
+			},
+		},
+	}
+}
+
+// snapshotSaveCommand builds the etcdctl invocation used both by the backup
+// CronJob and by `kubeadm etcd snapshot save`. When retention is greater than
+// zero, it appends a pruning step that keeps only the retention most recent
+// snapshot-*.db files under destDir, deleting the rest.
+func snapshotSaveCommand(clientURL, destDir string, retention int32) []string {
+	script := fmt.Sprintf("ETCDCTL_API=3 etcdctl --endpoints=%s --cacert=%s/%s --cert=%s/%s --key=%s/%s "+
+		"snapshot save %s/snapshot-$(date +%%Y%%m%%dT%%H%%M%%S).db",
+		clientURL,
+		constants.EtcdCertAndKeyDir, constants.EtcdCACertName,
+		constants.EtcdCertAndKeyDir, constants.EtcdHealthcheckClientCertName,
+		constants.EtcdCertAndKeyDir, constants.EtcdHealthcheckClientKeyName,
+		destDir)
+
+	if retention > 0 {
+		script += fmt.Sprintf(" && ls -1t %s/snapshot-*.db 2>/dev/null | tail -n +%d | xargs -r rm --",
+			destDir, retention+1)
+	}
+
+	return []string{"/bin/sh", "-ec", script}
+}
+
+// SaveSnapshot runs `etcdctl snapshot save` against the local etcd member
+// using the same invocation the backup CronJob uses, writing the snapshot
+// file under destDir. It backs `kubeadm etcd backup` and `kubeadm etcd
+// snapshot save`.
+func SaveSnapshot(endpoint *kubeadmapi.APIEndpoint, destDir string) error {
+	if destDir == "" {
+		destDir = destMountPath
+	}
+	clientURL := etcdutil.GetClientURLByIP(endpoint.AdvertiseAddress)
+	args := snapshotSaveCommand(clientURL, destDir, 0)
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("etcdctl snapshot save failed: %v\n%s", err, out)
+	}
+	return nil
+}
+
+func destinationVolume(dest kubeadmapi.BackupDestination) v1.Volume {
+	if dest.PersistentVolumeClaim != "" {
+		return v1.Volume{
+			Name: destVolumeName,
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dest.PersistentVolumeClaim,
+				},
+			},
+		}
+	}
+	return newHostPathVolume(destVolumeName, hostPathOrDefault(dest.HostPath))
+}
+
+func hostPathOrDefault(path string) string {
+	if path == "" {
+		return destMountPath
+	}
+	return path
+}
+
+func newHostPathVolume(name, path string) v1.Volume {
+	hostPathDirectoryOrCreate := v1.HostPathDirectoryOrCreate
+	return v1.Volume{
+		Name: name,
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{
+				Path: path,
+				Type: &hostPathDirectoryOrCreate,
+			},
+		},
+	}
+}
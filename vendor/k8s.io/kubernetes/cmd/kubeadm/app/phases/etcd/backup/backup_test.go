@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func TestGetSnapshotCronJob(t *testing.T) {
+	endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: "10.10.10.10"}
+
+	var tests = []struct {
+		name        string
+		local       *kubeadmapi.LocalEtcd
+		expectNil   bool
+		expectedSch string
+	}{
+		{
+			name:      "no local etcd config",
+			local:     nil,
+			expectNil: true,
+		},
+		{
+			name:      "no backup configured",
+			local:     &kubeadmapi.LocalEtcd{},
+			expectNil: true,
+		},
+		{
+			name:      "backup configured without a schedule",
+			local:     &kubeadmapi.LocalEtcd{Backup: &kubeadmapi.EtcdBackup{}},
+			expectNil: true,
+		},
+		{
+			name: "hostPath destination",
+			local: &kubeadmapi.LocalEtcd{
+				Backup: &kubeadmapi.EtcdBackup{
+					Schedule:    "0 * * * *",
+					Destination: kubeadmapi.BackupDestination{HostPath: "/var/backups/etcd"},
+				},
+			},
+			expectNil:   false,
+			expectedSch: "0 * * * *",
+		},
+		{
+			name: "PVC destination",
+			local: &kubeadmapi.LocalEtcd{
+				Backup: &kubeadmapi.EtcdBackup{
+					Schedule:    "0 */6 * * *",
+					Destination: kubeadmapi.BackupDestination{PersistentVolumeClaim: "etcd-backups"},
+				},
+			},
+			expectNil:   false,
+			expectedSch: "0 */6 * * *",
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			cronJob := GetSnapshotCronJob(rt.local, endpoint)
+			if rt.expectNil {
+				if cronJob != nil {
+					t.Fatalf("expected a nil CronJob, got %+v", cronJob)
+				}
+				return
+			}
+
+			if cronJob == nil {
+				t.Fatalf("expected a CronJob, got nil")
+			}
+			if cronJob.Spec.Schedule != rt.expectedSch {
+				t.Errorf("expected schedule %q, got %q", rt.expectedSch, cronJob.Spec.Schedule)
+			}
+
+			containers := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers
+			if len(containers) != 1 || containers[0].Name != "etcd-snapshot" {
+				t.Errorf("expected a single etcd-snapshot container, got %+v", containers)
+			}
+		})
+	}
+}
+
+func TestGetSnapshotCronJobRetention(t *testing.T) {
+	endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: "10.10.10.10"}
+
+	var tests = []struct {
+		name        string
+		retention   int32
+		expectPrune bool
+	}{
+		{name: "no retention configured", retention: 0, expectPrune: false},
+		{name: "retention configured", retention: 5, expectPrune: true},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			local := &kubeadmapi.LocalEtcd{
+				Backup: &kubeadmapi.EtcdBackup{
+					Schedule:    "0 * * * *",
+					Retention:   rt.retention,
+					Destination: kubeadmapi.BackupDestination{HostPath: "/var/backups/etcd"},
+				},
+			}
+
+			cronJob := GetSnapshotCronJob(local, endpoint)
+			command := strings.Join(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command, " ")
+
+			hasPrune := strings.Contains(command, "xargs -r rm")
+			if hasPrune != rt.expectPrune {
+				t.Errorf("expected pruning present=%v, got command: %s", rt.expectPrune, command)
+			}
+			if rt.expectPrune && !strings.Contains(command, "tail -n +6") {
+				t.Errorf("expected pruning to keep %d snapshots (tail -n +6), got command: %s", rt.retention, command)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// RestoreOptions carries the parameters needed to restore a local etcd member
+// from a snapshot file.
+type RestoreOptions struct {
+	// SnapshotPath is the path to the etcdctl snapshot file to restore from.
+	SnapshotPath string
+	// ManifestDir is the kubelet static pod manifest directory, e.g. /etc/kubernetes/manifests.
+	ManifestDir string
+	// NodeName is the name of the member being restored, used as --name.
+	NodeName string
+	// InitConfig supplies the advertise address used to compute the peer URL.
+	InitConfig *kubeadmapi.InitConfiguration
+}
+
+// Restore stops the local etcd static pod, restores etcd's data directory from
+// the given snapshot using `etcdctl snapshot restore`, and puts the static pod
+// manifest back so the kubelet restarts etcd against the restored data.
+//
+// On a multi-member cluster this must only be run against one member at a
+// time; Restore itself has no way to detect or prevent a concurrent restore
+// of another member. The `kubeadm etcd snapshot restore` CLI enforces this by
+// using etcdutil.CheckConfigurationIsHA to refuse to proceed against an HA
+// cluster unless the operator passes --force; other callers of Restore must
+// apply an equivalent check themselves before calling it.
+func Restore(opts RestoreOptions, local *kubeadmapi.LocalEtcd) error {
+	manifestPath := filepath.Join(opts.ManifestDir, "etcd.yaml")
+	// The kubelet loads every file in the manifest directory whatever its
+	// extension, so the manifest is parked one level up to stop the pod.
+	parkedManifestPath := filepath.Join(filepath.Dir(filepath.Clean(opts.ManifestDir)), "etcd.yaml.bak")
+
+	if err := os.Rename(manifestPath, parkedManifestPath); err != nil {
+		return fmt.Errorf("failed to stop the local etcd static pod: %v", err)
+	}
+
+	dataDir := constants.EtcdDataDir
+	if local != nil && local.DataDir != "" {
+		dataDir = local.DataDir
+	}
+
+	peerURL := etcdutil.GetPeerURL(opts.InitConfig)
+
+	cmd := exec.Command("etcdctl", //nolint:gosec
+		"snapshot", "restore", opts.SnapshotPath,
+		"--data-dir", dataDir,
+		"--name", opts.NodeName,
+		"--initial-cluster", fmt.Sprintf("%s=%s", opts.NodeName, peerURL),
+		"--initial-advertise-peer-urls", peerURL,
+	)
+	cmd.Env = append(os.Environ(), "ETCDCTL_API=3")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		// Put the manifest back so the cluster isn't left without etcd, even
+		// though the restore itself failed.
+		_ = os.Rename(parkedManifestPath, manifestPath)
+		return fmt.Errorf("etcdctl snapshot restore failed: %v\n%s", err, out)
+	}
+
+	if err := os.Rename(parkedManifestPath, manifestPath); err != nil {
+		return fmt.Errorf("failed to restart the local etcd static pod after restore: %v", err)
+	}
+	return nil
+}
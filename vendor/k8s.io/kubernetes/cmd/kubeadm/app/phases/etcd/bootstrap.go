@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+)
+
+const (
+	sansVolumeName = "etcd-bootstrap-sans"
+	sansMountPath  = "/etc/kubernetes/etcd-bootstrap"
+)
+
+// bootstrapCertsInitContainer builds the init container that self-requests the
+// node's etcd server/peer certs via a CertificateSigningRequest the first time
+// the static pod starts with no certs on disk. Extra SANs configured via
+// local.ServerCertSANs/PeerCertSANs are merged at runtime with whatever a
+// projected ConfigMap mounted at sansMountPath supplies, so per-node SANs
+// (e.g. a freshly joined member's IP) don't require rewriting the whole
+// cluster's configuration. Its command runs the kubeadm CLI, so it uses a
+// dedicated kubeadm-bearing image rather than the etcd image used elsewhere
+// in this pod.
+func bootstrapCertsInitContainer(local *kubeadmapi.LocalEtcd, nodeName string, spec clusterSpec) v1.Container {
+	command := []string{
+		"kubeadm", "etcd", "bootstrap-certs",
+		fmt.Sprintf("--cert-dir=%s", spec.certDir),
+		fmt.Sprintf("--node-name=%s", nodeName),
+		fmt.Sprintf("--san-config=%s", sansMountPath),
+	}
+	if sans := append(append([]string{}, local.ServerCertSANs...), local.PeerCertSANs...); len(sans) > 0 {
+		command = append(command, fmt.Sprintf("--extra-sans=%s", strings.Join(sans, ",")))
+	}
+
+	return v1.Container{
+		Name:    "etcd-bootstrap-certs",
+		Image:   bootstrapCertsImage(local),
+		Command: command,
+		VolumeMounts: []v1.VolumeMount{
+			{Name: certsVolumeName, MountPath: spec.certDir},
+			{Name: sansVolumeName, MountPath: sansMountPath, ReadOnly: true},
+		},
+	}
+}
+
+// bootstrapCertsImage returns the image to use for the etcd-bootstrap-certs
+// init container, falling back to kubeadm's default repository and tag.
+func bootstrapCertsImage(local *kubeadmapi.LocalEtcd) string {
+	repo := local.BootstrapCertsImage.ImageRepository
+	tag := local.BootstrapCertsImage.ImageTag
+	if repo == "" {
+		repo = constants.DefaultKubeadmImageRepository
+	}
+	if tag == "" {
+		tag = constants.DefaultKubeadmImageTag
+	}
+	return fmt.Sprintf("%s/%s:%s", repo, constants.KubeadmImageName, tag)
+}
+
+func bootstrapSANsVolume(local *kubeadmapi.LocalEtcd) v1.Volume {
+	return v1.Volume{
+		Name: sansVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{
+				Sources: []v1.VolumeProjection{
+					{
+						ConfigMap: &v1.ConfigMapProjection{
+							LocalObjectReference: v1.LocalObjectReference{Name: "etcd-bootstrap-sans"},
+							Optional:             boolPtr(true),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
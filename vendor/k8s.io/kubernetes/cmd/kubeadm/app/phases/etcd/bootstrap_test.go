@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"strings"
+	"testing"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+)
+
+func TestGetEtcdPodSpecBootstrapCerts(t *testing.T) {
+	var tests = []struct {
+		name             string
+		local            *kubeadmapi.LocalEtcd
+		expectedInitCtrs int
+	}{
+		{
+			name:             "bootstrap certs disabled",
+			local:            &kubeadmapi.LocalEtcd{},
+			expectedInitCtrs: 0,
+		},
+		{
+			name: "bootstrap certs enabled",
+			local: &kubeadmapi.LocalEtcd{
+				BootstrapCerts: true,
+				ServerCertSANs: []string{"etcd-0.example.com"},
+				PeerCertSANs:   []string{"10.0.0.1"},
+			},
+			expectedInitCtrs: 1,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			cfg := &kubeadmapi.ClusterConfiguration{
+				Etcd: kubeadmapi.Etcd{Local: rt.local},
+			}
+			endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: "127.0.0.1"}
+
+			spec := GetEtcdPodSpec(cfg, "node-1", endpoint)
+			if len(spec.InitContainers) != rt.expectedInitCtrs {
+				t.Fatalf("expected %d init containers, got %d", rt.expectedInitCtrs, len(spec.InitContainers))
+			}
+			if rt.expectedInitCtrs == 0 {
+				for _, v := range spec.Volumes {
+					if v.Name == sansVolumeName {
+						t.Errorf("expected no %q volume when BootstrapCerts is false", sansVolumeName)
+					}
+				}
+				return
+			}
+
+			initCtr := spec.InitContainers[0]
+			if initCtr.Name != "etcd-bootstrap-certs" {
+				t.Errorf("expected init container named %q, got %q", "etcd-bootstrap-certs", initCtr.Name)
+			}
+			expectedImage := constants.DefaultKubeadmImageRepository + "/" + constants.KubeadmImageName + ":" + constants.DefaultKubeadmImageTag
+			if initCtr.Image != expectedImage {
+				t.Errorf("expected init container to use the kubeadm image %q, got %q", expectedImage, initCtr.Image)
+			}
+			joined := strings.Join(initCtr.Command, " ")
+			if !strings.Contains(joined, "--node-name=node-1") {
+				t.Errorf("expected init container command to include --node-name=node-1, got: %v", initCtr.Command)
+			}
+			if !strings.Contains(joined, "--extra-sans=etcd-0.example.com,10.0.0.1") {
+				t.Errorf("expected init container command to merge ServerCertSANs/PeerCertSANs into --extra-sans, got: %v", initCtr.Command)
+			}
+
+			found := false
+			for _, v := range spec.Volumes {
+				if v.Name == sansVolumeName {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a %q volume when BootstrapCerts is true", sansVolumeName)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// certExpiryWarning is how far in advance of a certificate's expiry the
+// "cert-expiry" check group starts failing, giving operators a window to
+// rotate before the cluster is actually affected.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// CheckStatus is the outcome of a single Diagnostics check.
+type CheckStatus string
+
+const (
+	// CheckOK means the check passed.
+	CheckOK CheckStatus = "ok"
+	// CheckFailed means the check ran and found a problem.
+	CheckFailed CheckStatus = "failed"
+	// CheckSkipped means the check didn't run, e.g. because a prerequisite
+	// (such as a local etcd configuration) was absent.
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// CheckResult is the outcome of a single named check within a Diagnostics
+// run, grouped so report renderers can nest checks under their group (e.g.
+// one JUnit testsuite per group).
+type CheckResult struct {
+	Group  string
+	Name   string
+	Status CheckStatus
+	Err    error
+}
+
+// Diagnostics runs kubeadm's etcd health checks against a local etcd member:
+// manifest TLS configuration, HA configuration, endpoint reachability,
+// certificate expiry and member list consistency.
+type Diagnostics struct {
+	// ManifestDir is the directory containing the etcd static pod manifest.
+	ManifestDir string
+	// CertDir is the directory containing the etcd PKI material.
+	CertDir string
+	// Cfg is the cluster's etcd configuration.
+	Cfg *kubeadmapi.ClusterConfiguration
+	// Endpoint is the local API endpoint, used to compute the etcd client URL.
+	Endpoint *kubeadmapi.APIEndpoint
+	// DialTimeout bounds the endpoint reachability and member list checks.
+	// Defaults to 5 seconds when zero.
+	DialTimeout time.Duration
+}
+
+func (d *Diagnostics) dialTimeout() time.Duration {
+	if d.DialTimeout > 0 {
+		return d.DialTimeout
+	}
+	return 5 * time.Second
+}
+
+// Run executes every check and returns their results in a stable order:
+// manifest, configuration, reachability, cert-expiry, members.
+func (d *Diagnostics) Run() []CheckResult {
+	var results []CheckResult
+	results = append(results, d.checkManifest())
+	results = append(results, d.checkConfiguration())
+	results = append(results, d.checkReachability())
+	results = append(results, d.checkCertExpiry()...)
+	results = append(results, d.checkMembers())
+	return results
+}
+
+func (d *Diagnostics) checkManifest() CheckResult {
+	result := CheckResult{Group: "manifest", Name: "tls-configured"}
+
+	hasTLS, _, err := etcdutil.PodManifestsHaveTLS(d.ManifestDir)
+	if err != nil {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("failed to read etcd manifest: %v", err)
+		return result
+	}
+	if !hasTLS {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("etcd manifest in %q is not configured for TLS", d.ManifestDir)
+		return result
+	}
+
+	result.Status = CheckOK
+	return result
+}
+
+func (d *Diagnostics) checkConfiguration() CheckResult {
+	result := CheckResult{Group: "configuration", Name: "ha"}
+
+	if d.Cfg == nil {
+		result.Status = CheckSkipped
+		result.Err = fmt.Errorf("no etcd configuration provided")
+		return result
+	}
+
+	if !etcdutil.CheckConfigurationIsHA(&d.Cfg.Etcd) {
+		result.Status = CheckSkipped
+		result.Err = fmt.Errorf("etcd is not configured as an HA cluster")
+		return result
+	}
+
+	result.Status = CheckOK
+	return result
+}
+
+func (d *Diagnostics) checkReachability() CheckResult {
+	result := CheckResult{Group: "reachability", Name: "client-endpoint"}
+
+	if d.Endpoint == nil {
+		result.Status = CheckSkipped
+		result.Err = fmt.Errorf("no API endpoint provided")
+		return result
+	}
+
+	addr := net.JoinHostPort(d.Endpoint.AdvertiseAddress, fmt.Sprintf("%d", constants.EtcdListenClientPort))
+	conn, err := net.DialTimeout("tcp", addr, d.dialTimeout())
+	if err != nil {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("could not reach etcd client endpoint %q: %v", addr, err)
+		return result
+	}
+	conn.Close()
+
+	result.Status = CheckOK
+	return result
+}
+
+// checkCertExpiry returns one result per PKI file kubeadm expects in
+// d.CertDir, each failing if the certificate has already expired or expires
+// within certExpiryWarning.
+func (d *Diagnostics) checkCertExpiry() []CheckResult {
+	files := []string{
+		constants.EtcdServerCertName,
+		constants.EtcdPeerCertName,
+		constants.EtcdHealthcheckClientCertName,
+	}
+
+	results := make([]CheckResult, 0, len(files))
+	for _, name := range files {
+		results = append(results, d.checkOneCertExpiry(name))
+	}
+	return results
+}
+
+func (d *Diagnostics) checkOneCertExpiry(name string) CheckResult {
+	result := CheckResult{Group: "cert-expiry", Name: name}
+
+	path := filepath.Join(d.CertDir, name)
+	certPEM, err := ioutil.ReadFile(path)
+	if err != nil {
+		result.Status = CheckSkipped
+		result.Err = fmt.Errorf("could not read %q: %v", path, err)
+		return result
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("%q does not contain a PEM certificate", path)
+		return result
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("could not parse %q: %v", path, err)
+		return result
+	}
+
+	if remaining := time.Until(cert.NotAfter); remaining < certExpiryWarning {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("%q expires at %s (within %s)", path, cert.NotAfter.Format(time.RFC3339), certExpiryWarning)
+		return result
+	}
+
+	result.Status = CheckOK
+	return result
+}
+
+// checkMembers asks the local etcd member for its view of the cluster's
+// membership via etcd's v3 gRPC-gateway JSON API, so this check doesn't pull
+// in an etcd client library; a cluster with zero reported members is treated
+// as a failure the same as an unreachable endpoint.
+func (d *Diagnostics) checkMembers() CheckResult {
+	result := CheckResult{Group: "members", Name: "member-list"}
+
+	if d.Endpoint == nil {
+		result.Status = CheckSkipped
+		result.Err = fmt.Errorf("no API endpoint provided")
+		return result
+	}
+
+	cert, err := tls.LoadX509KeyPair(
+		filepath.Join(d.CertDir, constants.EtcdHealthcheckClientCertName),
+		filepath.Join(d.CertDir, constants.EtcdHealthcheckClientKeyName),
+	)
+	if err != nil {
+		result.Status = CheckSkipped
+		result.Err = fmt.Errorf("could not load healthcheck client certificate: %v", err)
+		return result
+	}
+
+	client := &http.Client{
+		Timeout: d.dialTimeout(),
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				Certificates:       []tls.Certificate{cert},
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	clientURL := etcdutil.GetClientURLByIP(d.Endpoint.AdvertiseAddress)
+	resp, err := client.Post(clientURL+"/v3/cluster/member/list", "application/json", nil)
+	if err != nil {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("failed to list cluster members: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("member list request returned status %d", resp.StatusCode)
+		return result
+	}
+
+	var memberList struct {
+		Members []json.RawMessage `json:"members"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&memberList); err != nil {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("failed to decode member list response: %v", err)
+		return result
+	}
+	if len(memberList.Members) == 0 {
+		result.Status = CheckFailed
+		result.Err = fmt.Errorf("member list reported zero members")
+		return result
+	}
+
+	result.Status = CheckOK
+	return result
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// ReportFormat selects how WriteReport renders a set of CheckResults.
+type ReportFormat string
+
+const (
+	// ReportFormatText renders one line of plain text per check.
+	ReportFormatText ReportFormat = "text"
+	// ReportFormatJSON renders the checks as a JSON array.
+	ReportFormatJSON ReportFormat = "json"
+	// ReportFormatJUnit renders the checks as a JUnit XML testsuites
+	// document, one testsuite per check group, modeled on the report shape
+	// kube-bench produces so the same CI tooling can consume either.
+	ReportFormatJUnit ReportFormat = "junit"
+)
+
+// AnyFailed returns true if any result has a CheckFailed status.
+func AnyFailed(results []CheckResult) bool {
+	for _, r := range results {
+		if r.Status == CheckFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteReport renders results in the given format to w.
+func WriteReport(w io.Writer, results []CheckResult, format ReportFormat) error {
+	switch format {
+	case ReportFormatText, "":
+		return writeTextReport(w, results)
+	case ReportFormatJSON:
+		return writeJSONReport(w, results)
+	case ReportFormatJUnit:
+		return writeJUnitReport(w, results)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func writeTextReport(w io.Writer, results []CheckResult) error {
+	for _, r := range results {
+		if r.Err != nil {
+			if _, err := fmt.Fprintf(w, "[%s] %s/%s: %v\n", r.Status, r.Group, r.Name, r.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "[%s] %s/%s\n", r.Status, r.Group, r.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonCheckResult mirrors CheckResult but with Err flattened to a string so
+// it survives JSON round-tripping without a custom (Un)MarshalJSON on the
+// exported type.
+type jsonCheckResult struct {
+	Group  string `json:"group"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func writeJSONReport(w io.Writer, results []CheckResult) error {
+	out := make([]jsonCheckResult, 0, len(results))
+	for _, r := range results {
+		jr := jsonCheckResult{Group: r.Group, Name: r.Name, Status: string(r.Status)}
+		if r.Err != nil {
+			jr.Error = r.Err.Error()
+		}
+		out = append(out, jr)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// junitTestSuites and friends model only the subset of the JUnit XML schema
+// kube-bench's own report emits: one testsuite per check group, one testcase
+// per check, with a failure or skipped child element carrying the message.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitMessage `xml:"failure,omitempty"`
+	Skip    *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+func writeJUnitReport(w io.Writer, results []CheckResult) error {
+	suites := buildJUnitSuites(results)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func buildJUnitSuites(results []CheckResult) junitTestSuites {
+	var suites junitTestSuites
+	indexByGroup := map[string]int{}
+
+	for _, r := range results {
+		idx, ok := indexByGroup[r.Group]
+		if !ok {
+			idx = len(suites.Suites)
+			indexByGroup[r.Group] = idx
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: r.Group})
+		}
+
+		tc := junitTestCase{Name: r.Name}
+		switch r.Status {
+		case CheckFailed:
+			suites.Suites[idx].Failures++
+			msg := ""
+			if r.Err != nil {
+				msg = r.Err.Error()
+			}
+			tc.Failure = &junitMessage{Message: msg}
+		case CheckSkipped:
+			suites.Suites[idx].Skipped++
+			msg := ""
+			if r.Err != nil {
+				msg = r.Err.Error()
+			}
+			tc.Skip = &junitMessage{Message: msg}
+		}
+		suites.Suites[idx].Tests++
+		suites.Suites[idx].Cases = append(suites.Suites[idx].Cases, tc)
+	}
+
+	return suites
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func sampleCheckResults() []CheckResult {
+	return []CheckResult{
+		{Group: "manifest", Name: "tls-configured", Status: CheckOK},
+		{Group: "configuration", Name: "ha", Status: CheckSkipped, Err: errors.New("etcd is not configured as an HA cluster")},
+		{Group: "reachability", Name: "client-endpoint", Status: CheckFailed, Err: errors.New("could not reach etcd client endpoint 127.0.0.1:2379")},
+		{Group: "cert-expiry", Name: "server.crt", Status: CheckOK},
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	var tests = []struct {
+		format ReportFormat
+		golden string
+	}{
+		{format: ReportFormatText, golden: "diagnostics.txt"},
+		{format: ReportFormatJSON, golden: "diagnostics.json"},
+		{format: ReportFormatJUnit, golden: "diagnostics.xml"},
+	}
+
+	for _, rt := range tests {
+		t.Run(string(rt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteReport(&buf, sampleCheckResults(), rt.format); err != nil {
+				t.Fatalf("WriteReport returned an error: %v", err)
+			}
+
+			want, err := ioutil.ReadFile(filepath.Join("testdata", rt.golden))
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+
+			if buf.String() != string(want) {
+				t.Errorf("WriteReport(%s) mismatch\ngot:\n%s\nwant:\n%s", rt.format, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestWriteReportUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, sampleCheckResults(), ReportFormat("yaml")); err == nil {
+		t.Error("expected an error for an unknown report format, got nil")
+	}
+}
+
+func TestAnyFailed(t *testing.T) {
+	if AnyFailed(sampleCheckResults()[:2]) {
+		t.Error("expected AnyFailed to be false without a failed check")
+	}
+	if !AnyFailed(sampleCheckResults()) {
+		t.Error("expected AnyFailed to be true with a failed check present")
+	}
+}
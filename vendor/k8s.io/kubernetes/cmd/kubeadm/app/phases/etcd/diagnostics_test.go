@@ -0,0 +1,192 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	testutil "k8s.io/kubernetes/cmd/kubeadm/test"
+)
+
+const validManifest = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: etcd
+  namespace: kube-system
+spec:
+  containers:
+  - command:
+    - etcd
+    - --cert-file=/etc/kubernetes/pki/etcd/server.crt
+    name: etcd
+  hostNetwork: true
+status: {}
+`
+
+func TestDiagnosticsCheckManifest(t *testing.T) {
+	tmpdir := testutil.SetupTempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := ioutil.WriteFile(filepath.Join(tmpdir, "etcd.yaml"), []byte(validManifest), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	d := &Diagnostics{ManifestDir: tmpdir}
+	result := d.checkManifest()
+	if result.Status != CheckOK {
+		t.Errorf("expected CheckOK, got %v (err: %v)", result.Status, result.Err)
+	}
+}
+
+func TestDiagnosticsCheckManifestMissing(t *testing.T) {
+	tmpdir := testutil.SetupTempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	d := &Diagnostics{ManifestDir: tmpdir}
+	result := d.checkManifest()
+	if result.Status != CheckFailed {
+		t.Errorf("expected CheckFailed for a missing manifest, got %v", result.Status)
+	}
+}
+
+func TestDiagnosticsCheckConfiguration(t *testing.T) {
+	var tests = []struct {
+		name     string
+		cfg      *kubeadmapi.ClusterConfiguration
+		expected CheckStatus
+	}{
+		{
+			name:     "no configuration provided",
+			cfg:      nil,
+			expected: CheckSkipped,
+		},
+		{
+			name:     "local etcd is not HA",
+			cfg:      &kubeadmapi.ClusterConfiguration{Etcd: kubeadmapi.Etcd{Local: &kubeadmapi.LocalEtcd{}}},
+			expected: CheckSkipped,
+		},
+		{
+			name: "HA external etcd",
+			cfg: &kubeadmapi.ClusterConfiguration{Etcd: kubeadmapi.Etcd{External: &kubeadmapi.ExternalEtcd{
+				Endpoints: []string{"10.0.0.1:2379", "10.0.0.2:2379", "10.0.0.3:2379"},
+			}}},
+			expected: CheckOK,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			d := &Diagnostics{Cfg: rt.cfg}
+			if result := d.checkConfiguration(); result.Status != rt.expected {
+				t.Errorf("expected %v, got %v (err: %v)", rt.expected, result.Status, result.Err)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsCheckReachability(t *testing.T) {
+	t.Run("no endpoint configured is skipped", func(t *testing.T) {
+		d := &Diagnostics{}
+		if result := d.checkReachability(); result.Status != CheckSkipped {
+			t.Errorf("expected CheckSkipped, got %v", result.Status)
+		}
+	})
+
+	t.Run("nothing listening on the client port fails", func(t *testing.T) {
+		d := &Diagnostics{
+			Endpoint:    &kubeadmapi.APIEndpoint{AdvertiseAddress: "127.0.0.1"},
+			DialTimeout: 200 * time.Millisecond,
+		}
+		if result := d.checkReachability(); result.Status != CheckFailed {
+			t.Errorf("expected CheckFailed when nothing is listening, got %v", result.Status)
+		}
+	})
+
+}
+
+func TestDiagnosticsCheckCertExpiry(t *testing.T) {
+	tmpdir := testutil.SetupTempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	writeCert(t, filepath.Join(tmpdir, constants.EtcdServerCertName), 365*24*time.Hour)
+	writeCert(t, filepath.Join(tmpdir, constants.EtcdPeerCertName), 5*24*time.Hour)
+	// EtcdHealthcheckClientCertName is left unwritten to exercise the
+	// missing-file path.
+
+	d := &Diagnostics{CertDir: tmpdir}
+	results := d.checkCertExpiry()
+	if len(results) != 3 {
+		t.Fatalf("expected 3 cert-expiry results, got %d", len(results))
+	}
+
+	byName := map[string]CheckResult{}
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	if r := byName[constants.EtcdServerCertName]; r.Status != CheckOK {
+		t.Errorf("expected the far-future cert to be CheckOK, got %v (err: %v)", r.Status, r.Err)
+	}
+	if r := byName[constants.EtcdPeerCertName]; r.Status != CheckFailed {
+		t.Errorf("expected the soon-to-expire cert to be CheckFailed, got %v", r.Status)
+	}
+	if r := byName[constants.EtcdHealthcheckClientCertName]; r.Status != CheckSkipped {
+		t.Errorf("expected the missing cert to be CheckSkipped, got %v", r.Status)
+	}
+}
+
+// writeCert writes a minimal self-signed certificate, valid until validFor
+// from now, to path.
+func writeCert(t *testing.T, path string, validFor time.Duration) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate a test key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "etcd-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create a test certificate: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := ioutil.WriteFile(path, certPEM, 0644); err != nil {
+		t.Fatalf("failed to write test certificate to %q: %v", path, err)
+	}
+}
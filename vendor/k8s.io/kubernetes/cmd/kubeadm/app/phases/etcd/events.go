@@ -0,0 +1,69 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+func eventsClusterSpec(endpoint *kubeadmapi.APIEndpoint) clusterSpec {
+	return clusterSpec{
+		podName:     "etcd-events",
+		dataDir:     constants.EventsEtcdDataDir,
+		certDir:     constants.EventsEtcdCertAndKeyDir,
+		clientPort:  constants.EventsEtcdListenClientPort,
+		peerPort:    constants.EventsEtcdListenPeerPort,
+		metricsPort: constants.EventsEtcdMetricsPort,
+		clientURL:   func(ep *kubeadmapi.APIEndpoint) string { return etcdutil.GetEventsClientURL(toInitConfiguration(ep)) },
+	}
+}
+
+func toInitConfiguration(endpoint *kubeadmapi.APIEndpoint) *kubeadmapi.InitConfiguration {
+	return &kubeadmapi.InitConfiguration{LocalAPIEndpoint: *endpoint}
+}
+
+// GetEventsEtcdPodSpec returns the v1.PodSpec for the dedicated events etcd
+// static pod. It is only meaningful when cfg.Etcd.Events.Local is set.
+func GetEventsEtcdPodSpec(cfg *kubeadmapi.ClusterConfiguration, nodeName string, endpoint *kubeadmapi.APIEndpoint) v1.PodSpec {
+	return getEtcdPodSpec(cfg.Etcd.Events.Local, nodeName, endpoint, eventsClusterSpec(endpoint))
+}
+
+// CreateLocalEventsEtcdStaticPodManifestFile renders the static pod manifest
+// for the dedicated events etcd member, if cfg.Etcd.Events.Local is set, and
+// writes it to manifestDir/etcd-events.yaml. It is a no-op when no events
+// cluster, or an external one, is configured.
+func CreateLocalEventsEtcdStaticPodManifestFile(manifestDir, nodeName string, cfg *kubeadmapi.ClusterConfiguration, endpoint *kubeadmapi.APIEndpoint) error {
+	if cfg.Etcd.Events == nil || cfg.Etcd.Events.Local == nil {
+		return nil
+	}
+
+	spec := eventsClusterSpec(endpoint)
+	pod := staticPodFromSpec(GetEventsEtcdPodSpec(cfg, nodeName, endpoint), spec.podName)
+
+	manifestPath := filepath.Join(manifestDir, spec.podName+".yaml")
+	if err := writePodToDisk(pod, manifestPath); err != nil {
+		return fmt.Errorf("failed to write static pod manifest file for the events etcd cluster to %q: %v", manifestPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func TestCreateLocalEventsEtcdStaticPodManifestFile(t *testing.T) {
+	var tests = []struct {
+		name           string
+		events         *kubeadmapi.Etcd
+		expectManifest bool
+	}{
+		{
+			name:           "no events cluster configured",
+			events:         nil,
+			expectManifest: false,
+		},
+		{
+			name:           "external events cluster is not locally managed",
+			events:         &kubeadmapi.Etcd{External: &kubeadmapi.ExternalEtcd{Endpoints: []string{"10.100.0.1:2382"}}},
+			expectManifest: false,
+		},
+		{
+			name:           "local events cluster",
+			events:         &kubeadmapi.Etcd{Local: &kubeadmapi.LocalEtcd{}},
+			expectManifest: true,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			tmpdir, err := ioutil.TempDir("", "kubeadm-test")
+			if err != nil {
+				t.Fatalf("couldn't create temporary directory: %v", err)
+			}
+			defer os.RemoveAll(tmpdir)
+
+			cfg := &kubeadmapi.ClusterConfiguration{
+				Etcd: kubeadmapi.Etcd{
+					Local:  &kubeadmapi.LocalEtcd{},
+					Events: rt.events,
+				},
+			}
+			endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: "10.10.10.10"}
+
+			if err := CreateLocalEventsEtcdStaticPodManifestFile(tmpdir, "node-1", cfg, endpoint); err != nil {
+				t.Fatalf("CreateLocalEventsEtcdStaticPodManifestFile returned an error: %v", err)
+			}
+
+			_, err = os.Stat(filepath.Join(tmpdir, "etcd-events.yaml"))
+			gotManifest := err == nil
+			if gotManifest != rt.expectManifest {
+				t.Errorf("expected manifest written: %v, got: %v", rt.expectManifest, gotManifest)
+			}
+		})
+	}
+}
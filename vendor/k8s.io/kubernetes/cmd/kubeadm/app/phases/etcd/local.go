@@ -0,0 +1,279 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcd implements kubeadm's logic for standing up and managing a
+// local, static-pod-based etcd cluster for the control plane.
+package etcd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+const (
+	etcdVolumeName      = "etcd-data"
+	certsVolumeName     = "etcd-certs"
+	manifestsVolumeName = "k8s-manifests"
+)
+
+// clusterSpec carries the per-cluster knobs that differ between the main
+// etcd cluster and the optional, dedicated events cluster: their ports,
+// PKI location and default data directory.
+type clusterSpec struct {
+	podName     string
+	dataDir     string
+	certDir     string
+	clientPort  int
+	peerPort    int
+	metricsPort int
+	clientURL   func(*kubeadmapi.APIEndpoint) string
+}
+
+func mainClusterSpec(endpoint *kubeadmapi.APIEndpoint) clusterSpec {
+	return clusterSpec{
+		podName:     "etcd",
+		dataDir:     constants.EtcdDataDir,
+		certDir:     constants.EtcdCertAndKeyDir,
+		clientPort:  constants.EtcdListenClientPort,
+		peerPort:    constants.EtcdListenPeerPort,
+		metricsPort: constants.EtcdMetricsPort,
+		clientURL:   func(ep *kubeadmapi.APIEndpoint) string { return etcdutil.GetClientURLByIP(ep.AdvertiseAddress) },
+	}
+}
+
+// CreateLocalEtcdStaticPodManifestFile renders the etcd static pod manifest for a
+// local etcd member and writes it to manifestDir/etcd.yaml.
+func CreateLocalEtcdStaticPodManifestFile(manifestDir, nodeName string, cfg *kubeadmapi.ClusterConfiguration, endpoint *kubeadmapi.APIEndpoint) error {
+	if cfg.Etcd.Local == nil {
+		return fmt.Errorf("etcd.CreateLocalEtcdStaticPodManifestFile called without a local etcd config")
+	}
+
+	spec := mainClusterSpec(endpoint)
+	pod := staticPodFromSpec(getEtcdPodSpec(cfg.Etcd.Local, nodeName, endpoint, spec), spec.podName)
+
+	manifestPath := filepath.Join(manifestDir, spec.podName+".yaml")
+	if err := writePodToDisk(pod, manifestPath); err != nil {
+		return fmt.Errorf("failed to write static pod manifest file for etcd to %q: %v", manifestPath, err)
+	}
+	return nil
+}
+
+// GetEtcdPodSpec returns the v1.PodSpec for the etcd static pod, including the
+// etcd container itself and, if configured, the long-running etcdctl sidecar
+// container and the bootstrap-certs init container.
+func GetEtcdPodSpec(cfg *kubeadmapi.ClusterConfiguration, nodeName string, endpoint *kubeadmapi.APIEndpoint) v1.PodSpec {
+	return getEtcdPodSpec(cfg.Etcd.Local, nodeName, endpoint, mainClusterSpec(endpoint))
+}
+
+func getEtcdPodSpec(local *kubeadmapi.LocalEtcd, nodeName string, endpoint *kubeadmapi.APIEndpoint, spec clusterSpec) v1.PodSpec {
+	image := etcdImage(local)
+
+	containers := []v1.Container{
+		etcdContainer(image, local, endpoint, spec),
+	}
+
+	if local.EtcdctlSidecar != nil {
+		containers = append(containers, etcdctlSidecarContainer(image, local, spec))
+	}
+
+	volumes := []v1.Volume{
+		newHostPathVolume(etcdVolumeName, dataDir(local, spec)),
+		newHostPathVolume(certsVolumeName, spec.certDir),
+	}
+
+	if local.EtcdctlSidecar != nil {
+		volumes = append(volumes, newHostPathVolume(manifestsVolumeName, manifestsDir()))
+	}
+
+	var initContainers []v1.Container
+	if local.BootstrapCerts {
+		initContainers = append(initContainers, bootstrapCertsInitContainer(local, nodeName, spec))
+		volumes = append(volumes, bootstrapSANsVolume(local))
+	}
+
+	return v1.PodSpec{
+		InitContainers: initContainers,
+		Containers:     containers,
+		HostNetwork:    true,
+		Volumes:        volumes,
+	}
+}
+
+func etcdContainer(image string, local *kubeadmapi.LocalEtcd, endpoint *kubeadmapi.APIEndpoint, spec clusterSpec) v1.Container {
+	clientURL := spec.clientURL(endpoint)
+	liveness, readiness, startup := etcdProbes(spec)
+	return v1.Container{
+		Name:           "etcd",
+		Image:          image,
+		Command:        etcdCommand(clientURL, dataDir(local, spec), spec),
+		LivenessProbe:  liveness,
+		ReadinessProbe: readiness,
+		StartupProbe:   startup,
+		VolumeMounts: []v1.VolumeMount{
+			{Name: etcdVolumeName, MountPath: dataDir(local, spec)},
+			{Name: certsVolumeName, MountPath: spec.certDir},
+		},
+	}
+}
+
+// etcdProbes builds the liveness, readiness and startup probes for the etcd
+// container. The client port enforces mutual TLS, and kubelet's httpGet
+// probes have no way to present a client certificate, so all three instead
+// hit etcd's /health endpoint over plain HTTP on the dedicated, loopback-only
+// metrics listener configured by etcdCommand's --listen-metrics-urls, the
+// same split real kubeadm uses. The readiness probe tolerates leader election
+// churn with a higher failure threshold than liveness, and the startup probe
+// absorbs slow first-boot so the old initialDelaySeconds hack on liveness is
+// no longer needed.
+func etcdProbes(spec clusterSpec) (liveness, readiness, startup *v1.Probe) {
+	handler := v1.Handler{
+		HTTPGet: &v1.HTTPGetAction{
+			Host:   "127.0.0.1",
+			Path:   "/health",
+			Port:   intstr.FromInt(spec.metricsPort),
+			Scheme: v1.URISchemeHTTP,
+		},
+	}
+
+	liveness = &v1.Probe{
+		Handler:          handler,
+		TimeoutSeconds:   15,
+		PeriodSeconds:    10,
+		FailureThreshold: 8,
+	}
+	readiness = &v1.Probe{
+		Handler:          handler,
+		TimeoutSeconds:   15,
+		PeriodSeconds:    10,
+		FailureThreshold: 24,
+	}
+	startup = &v1.Probe{
+		Handler:          handler,
+		TimeoutSeconds:   15,
+		PeriodSeconds:    10,
+		FailureThreshold: 24,
+	}
+	return liveness, readiness, startup
+}
+
+// etcdctlSidecarContainer builds the long-running sidecar that gives operators
+// a stable `kubectl exec` shell with ETCDCTL_API=3 etcdctl already able to reach
+// the local etcd member, without racing the etcd container's own liveness probe.
+// It mounts the same data and PKI directories as the etcd container, plus the
+// static pod manifest directory, so the shell can inspect both etcd's state
+// and the manifests that produced it.
+func etcdctlSidecarContainer(image string, local *kubeadmapi.LocalEtcd, spec clusterSpec) v1.Container {
+	sidecar := local.EtcdctlSidecar
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{},
+	}
+	if sidecar.CPURequest != "" {
+		resources.Requests[v1.ResourceCPU] = resource.MustParse(sidecar.CPURequest)
+	}
+	if sidecar.MemoryRequest != "" {
+		resources.Requests[v1.ResourceMemory] = resource.MustParse(sidecar.MemoryRequest)
+	}
+
+	return v1.Container{
+		Name:      "etcdctl",
+		Image:     image,
+		Command:   []string{"/bin/sh", "-ec", "trap : TERM INT; sleep infinity & wait"},
+		Resources: resources,
+		Env: []v1.EnvVar{
+			{Name: "ETCDCTL_API", Value: "3"},
+			{Name: "ETCDCTL_ENDPOINTS", Value: fmt.Sprintf("https://127.0.0.1:%d", spec.clientPort)},
+			{Name: "ETCDCTL_CACERT", Value: fmt.Sprintf("%s/%s", spec.certDir, constants.EtcdCACertName)},
+			{Name: "ETCDCTL_CERT", Value: fmt.Sprintf("%s/%s", spec.certDir, constants.EtcdHealthcheckClientCertName)},
+			{Name: "ETCDCTL_KEY", Value: fmt.Sprintf("%s/%s", spec.certDir, constants.EtcdHealthcheckClientKeyName)},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{Name: etcdVolumeName, MountPath: dataDir(local, spec)},
+			{Name: certsVolumeName, MountPath: spec.certDir},
+			{Name: manifestsVolumeName, MountPath: manifestsDir()},
+		},
+	}
+}
+
+func dataDir(local *kubeadmapi.LocalEtcd, spec clusterSpec) string {
+	if local != nil && local.DataDir != "" {
+		return local.DataDir
+	}
+	return spec.dataDir
+}
+
+// manifestsDir returns the host path where kubeadm keeps static pod
+// manifests, so the etcdctl sidecar can inspect them from its shell.
+func manifestsDir() string {
+	return filepath.Join(constants.KubernetesDir, constants.ManifestsSubDirName)
+}
+
+func etcdImage(local *kubeadmapi.LocalEtcd) string {
+	return EtcdImage(local)
+}
+
+// EtcdImage returns the etcd container image to use for the given local etcd
+// configuration, falling back to kubeadm's default repository and tag.
+func EtcdImage(local *kubeadmapi.LocalEtcd) string {
+	repo := local.ImageRepository
+	tag := local.ImageTag
+	if repo == "" {
+		repo = constants.DefaultEtcdImageRepository
+	}
+	if tag == "" {
+		tag = constants.DefaultEtcdImageTag
+	}
+	return fmt.Sprintf("%s/%s:%s", repo, constants.EtcdImageName, tag)
+}
+
+func etcdCommand(clientURL, localDataDir string, spec clusterSpec) []string {
+	return []string{
+		"etcd",
+		fmt.Sprintf("--advertise-client-urls=%s", clientURL),
+		fmt.Sprintf("--data-dir=%s", localDataDir),
+		fmt.Sprintf("--listen-client-urls=https://0.0.0.0:%d", spec.clientPort),
+		fmt.Sprintf("--listen-peer-urls=https://0.0.0.0:%d", spec.peerPort),
+		fmt.Sprintf("--listen-metrics-urls=http://127.0.0.1:%d", spec.metricsPort),
+		fmt.Sprintf("--cert-file=%s/%s", spec.certDir, constants.EtcdServerCertName),
+		fmt.Sprintf("--key-file=%s/%s", spec.certDir, constants.EtcdServerKeyName),
+		fmt.Sprintf("--trusted-ca-file=%s/%s", spec.certDir, constants.EtcdCACertName),
+		fmt.Sprintf("--peer-cert-file=%s/%s", spec.certDir, constants.EtcdPeerCertName),
+		fmt.Sprintf("--peer-key-file=%s/%s", spec.certDir, constants.EtcdPeerKeyName),
+		fmt.Sprintf("--peer-trusted-ca-file=%s/%s", spec.certDir, constants.EtcdCACertName),
+		"--peer-client-cert-auth=true",
+		"--client-cert-auth=true",
+	}
+}
+
+func newHostPathVolume(name, path string) v1.Volume {
+	hostPathDirectoryOrCreate := v1.HostPathDirectoryOrCreate
+	return v1.Volume{
+		Name: name,
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{
+				Path: path,
+				Type: &hostPathDirectoryOrCreate,
+			},
+		},
+	}
+}
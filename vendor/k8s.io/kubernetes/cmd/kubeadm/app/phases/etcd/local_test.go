@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"testing"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func TestGetEtcdPodSpecEtcdctlSidecar(t *testing.T) {
+	var tests = []struct {
+		name              string
+		sidecar           *kubeadmapi.EtcdctlSidecar
+		expectedContainer int
+	}{
+		{
+			name:              "no sidecar configured",
+			sidecar:           nil,
+			expectedContainer: 1,
+		},
+		{
+			name:              "sidecar configured",
+			sidecar:           &kubeadmapi.EtcdctlSidecar{CPURequest: "10m", MemoryRequest: "16Mi"},
+			expectedContainer: 2,
+		},
+	}
+
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			cfg := &kubeadmapi.ClusterConfiguration{
+				Etcd: kubeadmapi.Etcd{
+					Local: &kubeadmapi.LocalEtcd{
+						EtcdctlSidecar: rt.sidecar,
+					},
+				},
+			}
+			endpoint := &kubeadmapi.APIEndpoint{AdvertiseAddress: "127.0.0.1"}
+
+			spec := GetEtcdPodSpec(cfg, "node-1", endpoint)
+			if len(spec.Containers) != rt.expectedContainer {
+				t.Fatalf("expected %d containers, got %d", rt.expectedContainer, len(spec.Containers))
+			}
+
+			if rt.sidecar == nil {
+				return
+			}
+
+			found := false
+			for _, c := range spec.Containers {
+				if c.Name == "etcdctl" {
+					found = true
+					if len(c.Command) == 0 {
+						t.Errorf("expected etcdctl sidecar to have a command")
+					}
+				}
+			}
+			if !found {
+				t.Errorf("expected an etcdctl sidecar container, got containers: %+v", spec.Containers)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"io/ioutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// staticPodFromSpec wraps a v1.PodSpec into the v1.Pod shape kubelet expects
+// to find under /etc/kubernetes/manifests.
+func staticPodFromSpec(spec v1.PodSpec, podName string) *v1.Pod {
+	return &v1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: "kube-system",
+			Labels: map[string]string{
+				"component": "etcd",
+				"tier":      "control-plane",
+			},
+		},
+		Spec: spec,
+	}
+}
+
+// writePodToDisk marshals pod as YAML and writes it to path.
+func writePodToDisk(pod *v1.Pod, path string) error {
+	serialized, err := yaml.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, serialized, 0644)
+}
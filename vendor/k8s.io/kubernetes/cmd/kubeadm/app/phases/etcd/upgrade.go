@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+// UpgradeLivenessProbe rewrites an on-disk etcd static pod manifest whose
+// "etcd" container still uses the legacy `etcdctl ... get foo` exec liveness
+// probe to the current httpGet /health liveness, readiness and startup
+// probes, which target the unauthenticated metrics listener rather than the
+// mutual-TLS client port. Manifests already on the new shape, or missing
+// altogether, are left untouched, so this is safe to call unconditionally on
+// every upgrade.
+func UpgradeLivenessProbe(manifestDir string, metricsPort int) error {
+	manifestPath := filepath.Join(manifestDir, "etcd.yaml")
+	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	_, probe, err := etcdutil.PodManifestsHaveTLS(manifestDir)
+	if err != nil {
+		return err
+	}
+	if probe.Kind != etcdutil.ProbeKindExec {
+		return nil
+	}
+
+	podBytes, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read etcd manifest from %q: %v", manifestPath, err)
+	}
+
+	pod := &v1.Pod{}
+	if err := yaml.Unmarshal(podBytes, pod); err != nil {
+		return fmt.Errorf("failed to unmarshal etcd manifest from %q: %v", manifestPath, err)
+	}
+
+	liveness, readiness, startup := etcdProbes(clusterSpec{metricsPort: metricsPort})
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name != "etcd" {
+			continue
+		}
+		pod.Spec.Containers[i].LivenessProbe = liveness
+		pod.Spec.Containers[i].ReadinessProbe = readiness
+		pod.Spec.Containers[i].StartupProbe = startup
+	}
+
+	return writePodToDisk(pod, manifestPath)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	etcdutil "k8s.io/kubernetes/cmd/kubeadm/app/util/etcd"
+)
+
+const legacyExecProbeEtcdPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: etcd
+  namespace: kube-system
+spec:
+  containers:
+  - command:
+    - etcd
+    - --cert-file=/etc/kubernetes/pki/etcd/server.crt
+    image: k8s.gcr.io/etcd:3.4.3-0
+    livenessProbe:
+      exec:
+        command:
+        - /bin/sh
+        - -ec
+        - ETCDCTL_API=3 etcdctl get foo
+      failureThreshold: 8
+      initialDelaySeconds: 15
+      timeoutSeconds: 15
+    name: etcd
+    resources: {}
+status: {}
+`
+
+func TestUpgradeLivenessProbe(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "kubeadm-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	manifestPath := filepath.Join(tmpdir, "etcd.yaml")
+	if err := ioutil.WriteFile(manifestPath, []byte(legacyExecProbeEtcdPod), 0644); err != nil {
+		t.Fatalf("failed to write pod manifest: %v", err)
+	}
+
+	if err := UpgradeLivenessProbe(tmpdir, constants.EtcdMetricsPort); err != nil {
+		t.Fatalf("UpgradeLivenessProbe returned an error: %v", err)
+	}
+
+	podBytes, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read upgraded manifest: %v", err)
+	}
+	pod := &v1.Pod{}
+	if err := yaml.Unmarshal(podBytes, pod); err != nil {
+		t.Fatalf("failed to unmarshal upgraded manifest: %v", err)
+	}
+
+	if len(pod.Spec.Containers) != 1 {
+		t.Fatalf("expected a single container, got %d", len(pod.Spec.Containers))
+	}
+	probe := pod.Spec.Containers[0].LivenessProbe
+	if probe == nil || probe.HTTPGet == nil {
+		t.Fatalf("expected the liveness probe to be upgraded to httpGet, got %+v", probe)
+	}
+	if pod.Spec.Containers[0].ReadinessProbe == nil || pod.Spec.Containers[0].StartupProbe == nil {
+		t.Errorf("expected readiness and startup probes to be added")
+	}
+
+	// Running the upgrade again must be a no-op: PodManifestsHaveTLS should
+	// now report the httpGet probe kind and UpgradeLivenessProbe must not
+	// touch the file further.
+	_, probeInfo, err := etcdutil.PodManifestsHaveTLS(tmpdir)
+	if err != nil {
+		t.Fatalf("PodManifestsHaveTLS returned an error: %v", err)
+	}
+	if probeInfo.Kind != etcdutil.ProbeKindHTTPGet {
+		t.Errorf("expected probe kind %q after upgrade, got %q", etcdutil.ProbeKindHTTPGet, probeInfo.Kind)
+	}
+
+	if err := UpgradeLivenessProbe(tmpdir, constants.EtcdMetricsPort); err != nil {
+		t.Fatalf("second UpgradeLivenessProbe call returned an error: %v", err)
+	}
+}
+
+func TestUpgradeLivenessProbeNoManifest(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "kubeadm-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	// No etcd.yaml written to tmpdir: this exercises external-etcd setups and
+	// nodes that haven't been bootstrapped yet, where UpgradeLivenessProbe
+	// must be a safe no-op rather than returning a "file not found" error.
+	if err := UpgradeLivenessProbe(tmpdir, constants.EtcdMetricsPort); err != nil {
+		t.Fatalf("expected a missing manifest to be a no-op, got error: %v", err)
+	}
+}
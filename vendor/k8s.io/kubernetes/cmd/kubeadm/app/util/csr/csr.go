@@ -0,0 +1,102 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package csr provides helpers for self-requesting and waiting on
+// CertificateSigningRequests, used by kubeadm's etcd bootstrap init
+// container to materialize server/peer certs on first boot.
+package csr
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/certificate/csr"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// certificateWaitTimeout bounds how long RequestCertificate waits for the
+// kubeadm-etcd-approver controller to approve and the signer to issue a
+// submitted CertificateSigningRequest.
+const certificateWaitTimeout = 5 * time.Minute
+
+// RequestOptions describes the certificate kubeadm is requesting on behalf of
+// a node.
+type RequestOptions struct {
+	// Name is used both as the CertificateSigningRequest object name (suffixed
+	// with a random string by client-go) and as the certificate's CommonName.
+	Name string
+	// SignerName is the Kubernetes CSR signer that should issue the cert, e.g.
+	// the etcd.kubeadm.k8s.io/server or etcd.kubeadm.k8s.io/peer signer that
+	// the kubeadm-etcd-approver controller auto-approves.
+	SignerName string
+	// Usages are the requested key usages.
+	Usages []certificatesv1.KeyUsage
+	// DNSNames and IPAddresses become the certificate's Subject Alternative Names.
+	DNSNames    []string
+	IPAddresses []string
+}
+
+// RequestCertificate generates a private key, builds and submits a
+// CertificateSigningRequest for it, blocks until the request is approved and
+// issued, and returns the PEM-encoded key and certificate.
+func RequestCertificate(client clientset.Interface, opts RequestOptions) (key, cert []byte, err error) {
+	privateKey, err := keyutil.MakeEllipticPrivateKeyPEM()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate a private key for %q: %v", opts.Name, err)
+	}
+
+	signer, err := keyutil.ParsePrivateKeyPEM(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the generated private key for %q: %v", opts.Name, err)
+	}
+
+	csrPEM, err := certutil.MakeCSR(signer.(crypto.Signer), &pkix.Name{CommonName: opts.Name}, opts.DNSNames, parseIPs(opts.IPAddresses))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create a certificate request for %q: %v", opts.Name, err)
+	}
+
+	reqName, reqUID, err := csr.RequestCertificate(client, csrPEM, opts.Name+"-", opts.SignerName, opts.Usages, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to submit a certificate request for %q: %v", opts.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), certificateWaitTimeout)
+	defer cancel()
+
+	certPEM, err := csr.WaitForCertificate(ctx, client, reqName, reqUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed waiting for %q to be approved and issued: %v", opts.Name, err)
+	}
+
+	return privateKey, certPEM, nil
+}
+
+func parseIPs(addrs []string) []net.IP {
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRequestCertificateWaitsForIssuance proves RequestCertificate submits a
+// CSR and then actually blocks on its approval/issuance, rather than
+// returning as soon as the CSR is created.
+func TestRequestCertificateWaitsForIssuance(t *testing.T) {
+	client := fakeclientset.NewSimpleClientset()
+
+	const fakeCert = "-----BEGIN CERTIFICATE-----\nfake\n-----END CERTIFICATE-----\n"
+
+	go func() {
+		ctx := context.Background()
+		for i := 0; i < 100; i++ {
+			csrs, err := client.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+			if err == nil && len(csrs.Items) > 0 {
+				issued := csrs.Items[0].DeepCopy()
+				issued.Status.Certificate = []byte(fakeCert)
+				issued.Status.Conditions = append(issued.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+					Type:   certificatesv1.CertificateApproved,
+					Status: v1.ConditionTrue,
+					Reason: "AutoApproved",
+				})
+				if _, err := client.CertificatesV1().CertificateSigningRequests().UpdateStatus(ctx, issued, metav1.UpdateOptions{}); err == nil {
+					return
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	key, cert, err := RequestCertificate(client, RequestOptions{
+		Name:       "etcd-server-node-1",
+		SignerName: "etcd.kubeadm.k8s.io/server",
+		Usages:     []certificatesv1.KeyUsage{certificatesv1.UsageServerAuth},
+		DNSNames:   []string{"node-1"},
+	})
+	if err != nil {
+		t.Fatalf("RequestCertificate returned an error: %v", err)
+	}
+	if len(key) == 0 {
+		t.Errorf("expected a private key to be returned")
+	}
+	if string(cert) != fakeCert {
+		t.Errorf("expected the issued certificate to be returned, got %q", cert)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csr
+
+import (
+	"io/ioutil"
+	"os"
+
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// EnsureCert writes a key/cert pair to keyFile/certFile if either is missing,
+// requesting and waiting on a CertificateSigningRequest to materialize them.
+// It is a no-op, returning nil immediately, if both files already exist.
+func EnsureCert(client clientset.Interface, opts RequestOptions, certFile, keyFile string) error {
+	if fileExists(certFile) && fileExists(keyFile) {
+		return nil
+	}
+
+	key, cert, err := RequestCertificate(client, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(certFile, cert, 0644)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureCertSkipsWhenFilesExist(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "kubeadm-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	certFile := filepath.Join(tmpdir, "server.crt")
+	keyFile := filepath.Join(tmpdir, "server.key")
+	for _, f := range []string{certFile, keyFile} {
+		if err := ioutil.WriteFile(f, []byte("existing"), 0644); err != nil {
+			t.Fatalf("failed to write %q: %v", f, err)
+		}
+	}
+
+	// The fake clientset never issues a certificate, so if EnsureCert tried to
+	// submit a CSR it would block until certificateWaitTimeout and then fail;
+	// returning nil here proves the already-exists short-circuit fired.
+	client := fakeclientset.NewSimpleClientset()
+	if err := EnsureCert(client, RequestOptions{Name: "etcd-server"}, certFile, keyFile); err != nil {
+		t.Errorf("expected EnsureCert to skip existing files without error, got: %v", err)
+	}
+}
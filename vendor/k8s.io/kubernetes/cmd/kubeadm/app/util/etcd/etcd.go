@@ -0,0 +1,138 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcd provides small, dependency-free helpers for reasoning about
+// an etcd cluster's configuration and the static pod manifest kubeadm
+// generates for it. Manifest generation itself lives in
+// k8s.io/kubernetes/cmd/kubeadm/app/phases/etcd.
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+)
+
+// GetClientURL creates a URL that is equivalent to the local etcd member's client endpoint.
+func GetClientURL(localEndpoint *kubeadmapi.InitConfiguration) string {
+	return GetClientURLByIP(localEndpoint.LocalAPIEndpoint.AdvertiseAddress)
+}
+
+// GetPeerURL creates a URL that is equivalent to the local etcd member's peer endpoint.
+func GetPeerURL(localEndpoint *kubeadmapi.InitConfiguration) string {
+	return fmt.Sprintf("https://%s", net.JoinHostPort(localEndpoint.LocalAPIEndpoint.AdvertiseAddress, strconv.Itoa(constants.EtcdListenPeerPort)))
+}
+
+// GetClientURLByIP returns a URL for the client endpoint of an etcd member running on the given IP.
+func GetClientURLByIP(ip string) string {
+	return fmt.Sprintf("https://%s", net.JoinHostPort(ip, strconv.Itoa(constants.EtcdListenClientPort)))
+}
+
+// CheckConfigurationIsHA returns true if the given etcd configuration describes
+// an HA cluster, i.e. a cluster backed by more than one external endpoint.
+// A local etcd member is never considered HA by itself.
+func CheckConfigurationIsHA(cfg *kubeadmapi.Etcd) bool {
+	return cfg.External != nil && len(cfg.External.Endpoints) > 1
+}
+
+// GetEventsClientURL creates a URL that is equivalent to the local events etcd
+// member's client endpoint.
+func GetEventsClientURL(localEndpoint *kubeadmapi.InitConfiguration) string {
+	return fmt.Sprintf("https://%s", net.JoinHostPort(localEndpoint.LocalAPIEndpoint.AdvertiseAddress, strconv.Itoa(constants.EventsEtcdListenClientPort)))
+}
+
+// GetEventsPeerURL creates a URL that is equivalent to the local events etcd
+// member's peer endpoint.
+func GetEventsPeerURL(localEndpoint *kubeadmapi.InitConfiguration) string {
+	return fmt.Sprintf("https://%s", net.JoinHostPort(localEndpoint.LocalAPIEndpoint.AdvertiseAddress, strconv.Itoa(constants.EventsEtcdListenPeerPort)))
+}
+
+// CheckEventsConfigurationIsHA returns true if the given etcd configuration has
+// a dedicated events cluster and that cluster is itself HA.
+func CheckEventsConfigurationIsHA(cfg *kubeadmapi.Etcd) bool {
+	if cfg.Events == nil {
+		return false
+	}
+	return CheckConfigurationIsHA(cfg.Events)
+}
+
+// ProbeKind identifies the liveness probe mechanism found in an etcd static
+// pod manifest's "etcd" container.
+type ProbeKind string
+
+const (
+	// ProbeKindNone means no liveness probe was found on the etcd container.
+	ProbeKindNone ProbeKind = ""
+	// ProbeKindExec is the legacy `etcdctl ... get foo` exec probe.
+	ProbeKindExec ProbeKind = "exec"
+	// ProbeKindHTTPGet is the httpGet /health probe that replaced it.
+	ProbeKindHTTPGet ProbeKind = "httpGet"
+)
+
+// ProbeInfo describes the liveness probe kubeadm found on the "etcd" container
+// of an existing static pod manifest, so that callers can decide whether the
+// manifest needs to be upgraded to the current probe shape.
+type ProbeInfo struct {
+	Kind ProbeKind
+}
+
+// PodManifestsHaveTLS reads the etcd static pod manifest from manifestDir and
+// returns whether the etcd command line it contains is configured for TLS,
+// along with information about the etcd container's liveness probe.
+func PodManifestsHaveTLS(manifestDir string) (bool, ProbeInfo, error) {
+	manifestPath := filepath.Join(manifestDir, "etcd.yaml")
+	podBytes, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return false, ProbeInfo{}, fmt.Errorf("failed to read etcd manifest from %q: %v", manifestPath, err)
+	}
+
+	pod := &v1.Pod{}
+	if err := yaml.Unmarshal(podBytes, pod); err != nil {
+		return false, ProbeInfo{}, fmt.Errorf("failed to unmarshal etcd manifest from %q: %v", manifestPath, err)
+	}
+
+	hasTLS := false
+	probe := ProbeInfo{}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != "etcd" {
+			continue
+		}
+		for _, arg := range container.Command {
+			if strings.HasPrefix(arg, "--cert-file=") {
+				hasTLS = true
+			}
+		}
+		switch {
+		case container.LivenessProbe == nil:
+			probe.Kind = ProbeKindNone
+		case container.LivenessProbe.Exec != nil:
+			probe.Kind = ProbeKindExec
+		case container.LivenessProbe.HTTPGet != nil:
+			probe.Kind = ProbeKindHTTPGet
+		}
+	}
+
+	return hasTLS, probe, nil
+}
@@ -189,36 +189,169 @@ spec:
 status: {}
 `
 	invalidPod = `---{ broken yaml @@@`
+
+	// secureEtcdPodBootstrapCerts is a manifest whose etcd-bootstrap-certs init
+	// container has not yet run: the cert files its command line references
+	// don't exist anywhere on disk, including in the manifest directory itself.
+	// PodManifestsHaveTLS only parses the command line, so it must still report
+	// hasTLS=true without ever statting those paths.
+	secureEtcdPodBootstrapCerts = `
+apiVersion: v1
+kind: Pod
+metadata:
+  annotations:
+    scheduler.alpha.kubernetes.io/critical-pod: ""
+  creationTimestamp: null
+  labels:
+    component: etcd
+    tier: control-plane
+  name: etcd
+  namespace: kube-system
+spec:
+  initContainers:
+  - command:
+    - kubeadm
+    - etcd
+    - bootstrap-certs
+    - --cert-dir=/etc/kubernetes/pki/etcd
+    - --node-name=node-1
+    name: etcd-bootstrap-certs
+    image: k8s.gcr.io/etcd:3.4.3-0
+    volumeMounts:
+    - mountPath: /etc/kubernetes/pki/etcd
+      name: etcd-certs
+  containers:
+  - command:
+    - etcd
+    - --advertise-client-urls=https://127.0.0.1:2379
+    - --data-dir=/var/lib/etcd
+    - --cert-file=/etc/kubernetes/pki/etcd/server.crt
+    - --key-file=/etc/kubernetes/pki/etcd/server.key
+    image: k8s.gcr.io/etcd:3.4.3-0
+    livenessProbe:
+      httpGet:
+        path: /health
+        port: 2379
+        scheme: HTTPS
+      failureThreshold: 8
+      timeoutSeconds: 15
+    name: etcd
+    resources: {}
+    volumeMounts:
+    - mountPath: /var/lib/etcd
+      name: etcd-data
+    - mountPath: /etc/kubernetes/pki/etcd
+      name: etcd-certs
+  hostNetwork: true
+  volumes:
+  - hostPath:
+      path: /var/lib/etcd
+      type: DirectoryOrCreate
+    name: etcd-data
+  - hostPath:
+      path: /etc/kubernetes/pki/etcd
+      type: DirectoryOrCreate
+    name: etcd-certs
+status: {}
+`
+
+	secureEtcdPodHTTPGetProbe = `
+apiVersion: v1
+kind: Pod
+metadata:
+  annotations:
+    scheduler.alpha.kubernetes.io/critical-pod: ""
+  creationTimestamp: null
+  labels:
+    component: etcd
+    tier: control-plane
+  name: etcd
+  namespace: kube-system
+spec:
+  containers:
+  - command:
+    - etcd
+    - --advertise-client-urls=https://127.0.0.1:2379
+    - --data-dir=/var/lib/etcd
+    - --cert-file=/etc/kubernetes/pki/etcd/server.crt
+    - --key-file=/etc/kubernetes/pki/etcd/server.key
+    image: k8s.gcr.io/etcd:3.4.3-0
+    livenessProbe:
+      httpGet:
+        path: /health
+        port: 2379
+        scheme: HTTPS
+      failureThreshold: 8
+      timeoutSeconds: 15
+    name: etcd
+    resources: {}
+    volumeMounts:
+    - mountPath: /var/lib/etcd
+      name: etcd-data
+    - mountPath: /etc/kubernetes/pki/etcd
+      name: etcd-certs
+  hostNetwork: true
+  volumes:
+  - hostPath:
+      path: /var/lib/etcd
+      type: DirectoryOrCreate
+    name: etcd-data
+  - hostPath:
+      path: /etc/kubernetes/pki/etcd
+      type: DirectoryOrCreate
+    name: etcd-certs
+status: {}
+`
 )
 
 func TestPodManifestHasTLS(t *testing.T) {
 	tests := []struct {
-		description   string
-		podYaml       string
-		hasTLS        bool
-		expectErr     bool
-		writeManifest bool
+		description       string
+		podYaml           string
+		hasTLS            bool
+		expectedProbeKind ProbeKind
+		expectErr         bool
+		writeManifest     bool
 	}{
 		{
-			description:   "secure etcd returns true",
-			podYaml:       secureEtcdPod,
-			hasTLS:        true,
-			writeManifest: true,
-			expectErr:     false,
+			description:       "secure etcd returns true",
+			podYaml:           secureEtcdPod,
+			hasTLS:            true,
+			expectedProbeKind: ProbeKindExec,
+			writeManifest:     true,
+			expectErr:         false,
 		},
 		{
-			description:   "secure exposed etcd returns true",
-			podYaml:       secureExposedEtcdPod,
-			hasTLS:        true,
-			writeManifest: true,
-			expectErr:     false,
+			description:       "secure exposed etcd returns true",
+			podYaml:           secureExposedEtcdPod,
+			hasTLS:            true,
+			expectedProbeKind: ProbeKindExec,
+			writeManifest:     true,
+			expectErr:         false,
 		},
 		{
-			description:   "insecure etcd returns false",
-			podYaml:       insecureEtcdPod,
-			hasTLS:        false,
-			writeManifest: true,
-			expectErr:     false,
+			description:       "insecure etcd returns false",
+			podYaml:           insecureEtcdPod,
+			hasTLS:            false,
+			expectedProbeKind: ProbeKindHTTPGet,
+			writeManifest:     true,
+			expectErr:         false,
+		},
+		{
+			description:       "secure etcd already upgraded to the httpGet probe reports it as such",
+			podYaml:           secureEtcdPodHTTPGetProbe,
+			hasTLS:            true,
+			expectedProbeKind: ProbeKindHTTPGet,
+			writeManifest:     true,
+			expectErr:         false,
+		},
+		{
+			description:       "secure etcd whose bootstrap-certs init container hasn't materialized certs yet still returns true",
+			podYaml:           secureEtcdPodBootstrapCerts,
+			hasTLS:            true,
+			expectedProbeKind: ProbeKindHTTPGet,
+			writeManifest:     true,
+			expectErr:         false,
 		},
 		{
 			description:   "invalid pod fails to unmarshal",
@@ -248,7 +381,7 @@ func TestPodManifestHasTLS(t *testing.T) {
 			}
 		}
 
-		hasTLS, actualErr := PodManifestsHaveTLS(tmpdir)
+		hasTLS, probe, actualErr := PodManifestsHaveTLS(tmpdir)
 		if (actualErr != nil) != rt.expectErr {
 			t.Errorf(
 				"PodManifestHasTLS failed\n%s\n\texpected error: %t\n\tgot: %t\n\tactual error: %v",
@@ -262,6 +395,10 @@ func TestPodManifestHasTLS(t *testing.T) {
 		if hasTLS != rt.hasTLS {
 			t.Errorf("PodManifestHasTLS failed\n%s\n\texpected hasTLS: %t\n\tgot: %t", rt.description, rt.hasTLS, hasTLS)
 		}
+
+		if !rt.expectErr && probe.Kind != rt.expectedProbeKind {
+			t.Errorf("PodManifestHasTLS failed\n%s\n\texpected probe kind: %q\n\tgot: %q", rt.description, rt.expectedProbeKind, probe.Kind)
+		}
 	}
 }
 
@@ -398,3 +535,64 @@ func TestGetClientURLByIP(t *testing.T) {
 		}
 	}
 }
+
+func TestGetEventsClientURL(t *testing.T) {
+	testGetURL(t, GetEventsClientURL, constants.EventsEtcdListenClientPort)
+}
+
+func TestGetEventsPeerURL(t *testing.T) {
+	testGetURL(t, GetEventsPeerURL, constants.EventsEtcdListenPeerPort)
+}
+
+func TestCheckEventsConfigurationIsHA(t *testing.T) {
+	var tests = []struct {
+		name     string
+		cfg      *kubeadmapi.Etcd
+		expected bool
+	}{
+		{
+			name:     "no events cluster configured",
+			cfg:      &kubeadmapi.Etcd{},
+			expected: false,
+		},
+		{
+			name: "local events cluster is not HA",
+			cfg: &kubeadmapi.Etcd{
+				Events: &kubeadmapi.Etcd{
+					Local: &kubeadmapi.LocalEtcd{},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "single external events endpoint is not HA",
+			cfg: &kubeadmapi.Etcd{
+				Events: &kubeadmapi.Etcd{
+					External: &kubeadmapi.ExternalEtcd{
+						Endpoints: []string{"10.100.0.1:2382"},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "HA external events cluster",
+			cfg: &kubeadmapi.Etcd{
+				Events: &kubeadmapi.Etcd{
+					External: &kubeadmapi.ExternalEtcd{
+						Endpoints: []string{"10.100.0.1:2382", "10.100.0.2:2382", "10.100.0.3:2382"},
+					},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if isHA := CheckEventsConfigurationIsHA(test.cfg); isHA != test.expected {
+				t.Errorf("expected isHA to be %v, got %v", test.expected, isHA)
+			}
+		})
+	}
+}
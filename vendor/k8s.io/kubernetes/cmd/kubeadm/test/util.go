@@ -0,0 +1,33 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package test holds small helpers shared by kubeadm's unit tests.
+package test
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+// SetupTempDir creates a new temporary directory for use in a test and
+// fails the test immediately if the directory could not be created.
+func SetupTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "kubeadm-test")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %v", err)
+	}
+	return dir
+}